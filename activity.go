@@ -22,8 +22,13 @@ type Activity struct {
 	ForeignID string
 	Data      *json.RawMessage
 	MetaData  map[string]string
+	// Extra holds the custom fields which aren't strings (numbers, lists, objects) as raw JSON
+	Extra map[string]*json.RawMessage
 
 	To []Feed
+
+	// Score is the ranking score, only returned when reading with a ranking method
+	Score float64
 }
 
 // MarshalJSON is the custom marshal function for Activities
@@ -32,6 +37,9 @@ func (a Activity) MarshalJSON() ([]byte, error) {
 
 	payload := make(map[string]interface{})
 
+	for key, value := range a.Extra {
+		payload[key] = value
+	}
 	for key, value := range a.MetaData {
 		payload[key] = value
 	}
@@ -85,6 +93,7 @@ func (a *Activity) UnmarshalJSON(b []byte) (err error) {
 
 	rawPayload := make(map[string]*json.RawMessage)
 	metadata := make(map[string]string)
+	extra := make(map[string]*json.RawMessage)
 
 	err = json.Unmarshal(b, &rawPayload)
 	if err != nil {
@@ -137,6 +146,13 @@ func (a *Activity) UnmarshalJSON(b []byte) (err error) {
 				continue
 			}
 			a.TimeStamp = &timeStamp
+		} else if lowerKey == "score" {
+			var floatValue float64
+			err := json.Unmarshal(*value, &floatValue)
+			if err != nil {
+				continue
+			}
+			a.Score = floatValue
 		} else if lowerKey == "data" {
 			a.Data = value
 		} else if lowerKey == "to" {
@@ -200,12 +216,19 @@ func (a *Activity) UnmarshalJSON(b []byte) (err error) {
 			}
 		} else {
 			var strValue string
-			json.Unmarshal(*value, &strValue)
+			err := json.Unmarshal(*value, &strValue)
+			if err != nil {
+				extra[key] = value
+				continue
+			}
 			metadata[key] = strValue
 		}
 	}
 
 	a.MetaData = metadata
+	if len(extra) > 0 {
+		a.Extra = extra
+	}
 	return nil
 
 }
@@ -0,0 +1,279 @@
+package getstream
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnrichedField is an Actor, Object or Target of an EnrichedActivity
+// The API returns either the plain string that was stored or, when the value
+// referenced a user ("SU:id") or a collection entry ("SO:collection:id"), the embedded object
+type EnrichedField struct {
+	// Value is the plain string, or the "id" of the embedded object
+	Value string
+	// Object is the decoded embedded object, nil when the field is a plain string
+	Object map[string]interface{}
+
+	raw json.RawMessage
+}
+
+// IsObject reports whether the field was returned as an embedded object
+func (e EnrichedField) IsObject() bool {
+	return e.Object != nil
+}
+
+// Decode unmarshals the embedded object into v
+// For a plain string field the string itself is unmarshalled into v
+func (e EnrichedField) Decode(v interface{}) error {
+	if e.raw == nil {
+		return json.Unmarshal([]byte(strconv.Quote(e.Value)), v)
+	}
+	return json.Unmarshal(e.raw, v)
+}
+
+// String returns the plain string value, or the id of the embedded object
+func (e EnrichedField) String() string {
+	return e.Value
+}
+
+// MarshalJSON is the custom marshal function for EnrichedFields
+func (e EnrichedField) MarshalJSON() ([]byte, error) {
+	if e.raw != nil {
+		return e.raw, nil
+	}
+	return json.Marshal(e.Value)
+}
+
+// UnmarshalJSON is the custom unmarshal function for EnrichedFields
+func (e *EnrichedField) UnmarshalJSON(b []byte) error {
+	var strValue string
+	if err := json.Unmarshal(b, &strValue); err == nil {
+		e.Value = strValue
+		e.Object = nil
+		e.raw = nil
+		return nil
+	}
+
+	object := make(map[string]interface{})
+	if err := json.Unmarshal(b, &object); err != nil {
+		return err
+	}
+
+	e.Object = object
+	e.raw = append(json.RawMessage(nil), b...)
+	e.Value = ""
+	if id, ok := object["id"].(string); ok {
+		e.Value = id
+	}
+	return nil
+}
+
+// Reaction is a reaction (like, comment, ...) returned with an EnrichedActivity
+type Reaction struct {
+	ID         string                 `json:"id"`
+	Kind       string                 `json:"kind"`
+	ActivityID string                 `json:"activity_id"`
+	UserID     string                 `json:"user_id"`
+	User       *EnrichedField         `json:"user,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	CreatedAt  string                 `json:"created_at"`
+	UpdatedAt  string                 `json:"updated_at"`
+}
+
+// EnrichedActivity is an Activity returned from an enriched feed read
+// Actor, Object and Target may be plain strings or embedded objects
+type EnrichedActivity struct {
+	ID        string
+	Actor     EnrichedField
+	Verb      string
+	Object    EnrichedField
+	Target    EnrichedField
+	Origin    FeedID
+	TimeStamp *time.Time
+
+	ForeignID string
+	Data      *json.RawMessage
+	MetaData  map[string]string
+	// Extra holds the custom fields which aren't strings (numbers, lists, objects) as raw JSON
+	Extra map[string]*json.RawMessage
+
+	To []Feed
+
+	// Score is the ranking score, only returned when reading with a ranking method
+	Score float64
+
+	ReactionCounts  map[string]int
+	OwnReactions    map[string][]*Reaction
+	LatestReactions map[string][]*Reaction
+}
+
+// UnmarshalJSON is the custom unmarshal function for EnrichedActivities
+// It will be used by json.Unmarshal()
+func (a *EnrichedActivity) UnmarshalJSON(b []byte) (err error) {
+
+	rawPayload := make(map[string]*json.RawMessage)
+
+	err = json.Unmarshal(b, &rawPayload)
+	if err != nil {
+		return err
+	}
+
+	// everything that isn't enriched is handled like a regular Activity
+	remainder := make(map[string]*json.RawMessage)
+
+	for key, value := range rawPayload {
+		lowerKey := strings.ToLower(key)
+
+		if value == nil {
+			continue
+		}
+
+		if lowerKey == "actor" {
+			err = json.Unmarshal(*value, &a.Actor)
+		} else if lowerKey == "object" {
+			err = json.Unmarshal(*value, &a.Object)
+		} else if lowerKey == "target" {
+			err = json.Unmarshal(*value, &a.Target)
+		} else if lowerKey == "reaction_counts" {
+			err = json.Unmarshal(*value, &a.ReactionCounts)
+		} else if lowerKey == "own_reactions" {
+			err = json.Unmarshal(*value, &a.OwnReactions)
+		} else if lowerKey == "latest_reactions" {
+			err = json.Unmarshal(*value, &a.LatestReactions)
+		} else {
+			remainder[key] = value
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	remainderBytes, err := json.Marshal(remainder)
+	if err != nil {
+		return err
+	}
+
+	activity := Activity{}
+	err = json.Unmarshal(remainderBytes, &activity)
+	if err != nil {
+		return err
+	}
+
+	a.ID = activity.ID
+	a.Verb = activity.Verb
+	a.Origin = activity.Origin
+	a.TimeStamp = activity.TimeStamp
+	a.ForeignID = activity.ForeignID
+	a.Data = activity.Data
+	a.MetaData = activity.MetaData
+	a.Extra = activity.Extra
+	a.To = activity.To
+	a.Score = activity.Score
+
+	return nil
+}
+
+// EnrichedActivityGroup is a group of EnrichedActivities returned from an enriched
+// Aggregated or Notification Feed read. IsRead and IsSeen are only set for Notification Feeds
+type EnrichedActivityGroup struct {
+	Activities    []*EnrichedActivity `json:"activities"`
+	ActivityCount int                 `json:"activity_count"`
+	ActorCount    int                 `json:"actor_count"`
+	CreatedAt     time.Time           `json:"created_at"`
+	Group         string              `json:"group"`
+	ID            string              `json:"id"`
+	IsRead        bool                `json:"is_read"`
+	IsSeen        bool                `json:"is_seen"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	Verb          string              `json:"verb"`
+}
+
+// MarshalJSON is the custom marshal function for EnrichedActivityGroups
+// It writes the timestamps in the format of the API, which UnmarshalJSON reads back
+func (g EnrichedActivityGroup) MarshalJSON() ([]byte, error) {
+	type group EnrichedActivityGroup
+
+	return json.Marshal(struct {
+		group
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{
+		group:     group(g),
+		CreatedAt: formatTime(g.CreatedAt),
+		UpdatedAt: formatTime(g.UpdatedAt),
+	})
+}
+
+// UnmarshalJSON is the custom unmarshal function for EnrichedActivityGroups
+// It parses the timestamps the same way as AggregatedGroup and NotificationGroup
+func (g *EnrichedActivityGroup) UnmarshalJSON(b []byte) error {
+	type group EnrichedActivityGroup
+
+	payload := struct {
+		*group
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{
+		group: (*group)(g),
+	}
+
+	err := json.Unmarshal(b, &payload)
+	if err != nil {
+		return err
+	}
+
+	if createdAt, err := parseTime(payload.CreatedAt); err == nil {
+		g.CreatedAt = createdAt
+	}
+	if updatedAt, err := parseTime(payload.UpdatedAt); err == nil {
+		g.UpdatedAt = updatedAt
+	}
+
+	return nil
+}
+
+// GetEnrichedFlatFeedOutput is the response from a FlatFeed EnrichedActivities Get Request
+type GetEnrichedFlatFeedOutput struct {
+	Duration   string              `json:"duration"`
+	Next       string              `json:"next"`
+	Activities []*EnrichedActivity `json:"results"`
+}
+
+// GetEnrichedAggregatedFeedOutput is the response from a AggregatedFeed EnrichedActivities Get Request
+type GetEnrichedAggregatedFeedOutput struct {
+	Duration string                   `json:"duration"`
+	Next     string                   `json:"next"`
+	Results  []*EnrichedActivityGroup `json:"results"`
+}
+
+// GetEnrichedNotificationFeedOutput is the response from a NotificationFeed EnrichedActivities Get Request
+type GetEnrichedNotificationFeedOutput struct {
+	Duration string                   `json:"duration"`
+	Next     string                   `json:"next"`
+	Results  []*EnrichedActivityGroup `json:"results"`
+	Unread   int                      `json:"unread"`
+	Unseen   int                      `json:"unseen"`
+}
+
+// enrichParams builds the query params for the reaction options of an enriched read
+func enrichParams(withReactionCounts bool, withOwnReactions bool, withRecentReactions bool, recentReactionsLimit int) map[string]string {
+	params := map[string]string{}
+
+	if withReactionCounts {
+		params["withReactionCounts"] = "true"
+	}
+	if withOwnReactions {
+		params["withOwnReactions"] = "true"
+	}
+	if withRecentReactions {
+		params["withRecentReactions"] = "true"
+		if recentReactionsLimit > 0 {
+			params["recentReactionsLimit"] = strconv.Itoa(recentReactionsLimit)
+		}
+	}
+
+	return params
+}
@@ -0,0 +1,176 @@
+package getstream_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestEnrichedActivityUnmarshall(t *testing.T) {
+	activity := &getstream.EnrichedActivity{}
+	payload := []byte(`{
+		"id": "ef696c12-69ab-11e4-8080-80003644b625",
+		"actor": {"id": "bob", "data": {"name": "Bob"}},
+		"verb": "post",
+		"object": "SO:post:1",
+		"foreign_id": "post:1",
+		"time": "2016-09-22T21:44:58.821577",
+		"popularity": "9",
+		"reaction_counts": {"like": 2},
+		"own_reactions": {"like": [{"id": "r1", "kind": "like", "user_id": "bob"}]}
+	}`)
+
+	err := activity.UnmarshalJSON(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !activity.Actor.IsObject() || activity.Actor.Value != "bob" {
+		t.Fatal("Expected actor to be an embedded object with id bob, got:", activity.Actor)
+	}
+
+	var actor struct {
+		Data struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	err = activity.Actor.Decode(&actor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actor.Data.Name != "Bob" {
+		t.Fatal("Expected decoded actor name Bob, got:", actor.Data.Name)
+	}
+
+	if activity.Object.IsObject() || activity.Object.Value != "SO:post:1" {
+		t.Fatal("Expected object to be a plain string, got:", activity.Object)
+	}
+
+	if activity.ID != "ef696c12-69ab-11e4-8080-80003644b625" || activity.Verb != "post" || activity.ForeignID != "post:1" {
+		t.Fatal("Expected regular activity fields to be set, got:", activity)
+	}
+	if activity.TimeStamp == nil {
+		t.Fatal("Expected time to be parsed")
+	}
+	if activity.MetaData["popularity"] != "9" {
+		t.Fatal("Expected custom fields in MetaData, got:", activity.MetaData)
+	}
+	if _, ok := activity.MetaData["reaction_counts"]; ok {
+		t.Fatal("Expected reaction fields to be excluded from MetaData")
+	}
+
+	if activity.ReactionCounts["like"] != 2 {
+		t.Fatal("Expected 2 likes, got:", activity.ReactionCounts)
+	}
+	if len(activity.OwnReactions["like"]) != 1 || activity.OwnReactions["like"][0].UserID != "bob" {
+		t.Fatal("Expected own like reaction by bob, got:", activity.OwnReactions)
+	}
+}
+
+func TestEnrichedActivityUnmarshallBadActor(t *testing.T) {
+	activity := &getstream.EnrichedActivity{}
+
+	err := activity.UnmarshalJSON([]byte(`{"actor": 1}`))
+	if err == nil {
+		t.Fatal("Expected an error for a numeric actor")
+	}
+}
+
+func TestEnrichedActivityGroupJSON(t *testing.T) {
+	group := &getstream.EnrichedActivityGroup{}
+	err := json.Unmarshal([]byte(`{"id": "g1", "verb": "like", "created_at": "2017-05-25T12:52:42.571262", "updated_at": "2017-05-26T08:00:00"}`), group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != "g1" || !group.CreatedAt.Equal(time.Date(2017, 5, 25, 12, 52, 42, 571262000, time.UTC)) {
+		t.Fatal("Unexpected group:", group)
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"created_at":"2017-05-25T12:52:42.571262"`) || !strings.Contains(string(data), `"updated_at":"2017-05-26T08:00:00"`) {
+		t.Fatal("Expected the timestamps in the API format, got:", string(data))
+	}
+
+	decoded := &getstream.EnrichedActivityGroup{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreatedAt.Equal(group.CreatedAt) || !decoded.UpdatedAt.Equal(group.UpdatedAt) || decoded.Verb != "like" {
+		t.Fatal("Expected the group to survive a round trip, got:", decoded)
+	}
+}
+
+func TestFlatFeedEnrichedActivities(t *testing.T) {
+	var requestURL string
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		requestURL = r.URL.String()
+		w.Write([]byte(`{"duration": "1ms", "results": [{"id": "1", "actor": {"id": "bob"}, "verb": "post", "object": "post:1"}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := feed.EnrichedActivities(&getstream.GetFlatFeedInput{
+		WithReactionCounts:   true,
+		WithRecentReactions:  true,
+		RecentReactionsLimit: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(output.Activities) != 1 || output.Activities[0].Actor.Value != "bob" {
+		t.Fatal("Expected one enriched activity by bob, got:", output.Activities)
+	}
+
+	expected := "/api/v1.0/enrich/feed/flat/bob/?api_key=my_key&location=unspecified&recentReactionsLimit=5&withReactionCounts=true&withRecentReactions=true"
+	if requestURL != expected {
+		t.Fatal("Expected request to", expected, "got:", requestURL)
+	}
+}
+
+func TestNotificationFeedEnrichedActivities(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"unread": 1, "unseen": 2, "results": [{"id": "g1", "is_read": true, "created_at": "2017-05-25T12:52:42.571262", "activities": [{"id": "1", "actor": "bob", "verb": "like", "object": {"id": "post:1"}}]}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := feed.EnrichedActivities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if output.Unread != 1 || output.Unseen != 2 || len(output.Results) != 1 {
+		t.Fatal("Unexpected notification output:", output)
+	}
+	group := output.Results[0]
+	if !group.IsRead || len(group.Activities) != 1 || !group.Activities[0].Object.IsObject() {
+		t.Fatal("Unexpected notification group:", group)
+	}
+	if group.CreatedAt.Year() != 2017 {
+		t.Fatal("Expected created_at to be parsed, got:", group.CreatedAt)
+	}
+}
@@ -1,6 +1,7 @@
 package getstream_test
 
 import (
+	"encoding/json"
 	"errors"
 	"testing"
 
@@ -126,3 +127,50 @@ func TestActivityUnmarshallBadPayloadTo(t *testing.T) {
 		t.Fatal("To payload was not a value feedslug:userid format, expected To to be nil afterward, got:", activity.To)
 	}
 }
+
+func TestActivityUnmarshallScore(t *testing.T) {
+	activity := &getstream.Activity{}
+	payload := []byte(`{"actor":"flat:john","object":"flat:eric","verb":"post","score":12.5,"popularity":"high"}`)
+
+	err := activity.UnmarshalJSON(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if activity.Score != 12.5 {
+		t.Fatal("Expected score 12.5, got:", activity.Score)
+	}
+	if _, ok := activity.MetaData["score"]; ok {
+		t.Fatal("Expected score to be excluded from MetaData")
+	}
+	if activity.MetaData["popularity"] != "high" {
+		t.Fatal("Expected custom fields in MetaData, got:", activity.MetaData)
+	}
+}
+
+func TestActivityExtraRoundTrip(t *testing.T) {
+	activity := &getstream.Activity{}
+	payload := []byte(`{"actor":"flat:john","object":"flat:eric","verb":"post","popularity":"high","likes":12,"tags":["a","b"],"location":{"city":"Amsterdam"}}`)
+
+	err := json.Unmarshal(payload, activity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(activity.MetaData) != 1 || activity.MetaData["popularity"] != "high" {
+		t.Fatal("Expected only string fields in MetaData, got:", activity.MetaData)
+	}
+	if len(activity.Extra) != 3 || string(*activity.Extra["likes"]) != "12" {
+		t.Fatal("Expected the other custom fields in Extra, got:", activity.Extra)
+	}
+
+	result, err := json.Marshal(activity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := map[string]interface{}{}
+	json.Unmarshal(result, &fields)
+	if fields["likes"] != 12.0 || fields["location"].(map[string]interface{})["city"] != "Amsterdam" || fields["popularity"] != "high" {
+		t.Fatal("Expected custom fields to be marshalled as they were, got:", string(result))
+	}
+}
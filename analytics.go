@@ -0,0 +1,319 @@
+package getstream
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrAnalyticsBufferFull is returned when an event is dropped because the buffer is full
+var ErrAnalyticsBufferFull = errors.New("analytics buffer is full, event dropped")
+
+// ErrAnalyticsClosed is returned when tracking events after Close
+var ErrAnalyticsClosed = errors.New("analytics client is closed")
+
+// AnalyticsDropPolicy decides which event is dropped when the buffer is full
+type AnalyticsDropPolicy uint32
+
+const (
+	// AnalyticsDropNewest : the event being tracked is dropped
+	AnalyticsDropNewest AnalyticsDropPolicy = 0
+	// AnalyticsDropOldest : the oldest buffered event, Impression or Engagement, is dropped to make room
+	AnalyticsDropOldest AnalyticsDropPolicy = 1
+)
+
+// AnalyticsUser identifies the user an event belongs to
+type AnalyticsUser struct {
+	ID    string `json:"id"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// Impression is an analytics event for content shown to a user
+// ContentList holds the foreign ids of the content
+type Impression struct {
+	ContentList []string       `json:"content_list"`
+	FeedID      string         `json:"feed_id,omitempty"`
+	Location    string         `json:"location,omitempty"`
+	Position    int            `json:"position,omitempty"`
+	UserData    *AnalyticsUser `json:"user_data"`
+}
+
+// Engagement is an analytics event for a user interacting with content
+// Content is the foreign id of the content, Label the kind of engagement ("click", "like", ...)
+type Engagement struct {
+	Content  string         `json:"content"`
+	Label    string         `json:"label"`
+	Score    int            `json:"score,omitempty"`
+	FeedID   string         `json:"feed_id,omitempty"`
+	Location string         `json:"location,omitempty"`
+	Position int            `json:"position,omitempty"`
+	UserData *AnalyticsUser `json:"user_data"`
+}
+
+// AnalyticsConfig configures the batching of an Analytics client
+// Zero values are replaced by the defaults
+type AnalyticsConfig struct {
+	// BatchSize is the maximum number of Engagements per request, defaults to 100,
+	// Impressions are sent one per request
+	BatchSize int
+	// FlushInterval is how often buffered events are sent, defaults to 5 seconds
+	FlushInterval time.Duration
+	// BufferSize is the maximum number of buffered events, defaults to 10000
+	BufferSize int
+	// DropPolicy decides which event is dropped when the buffer is full
+	DropPolicy AnalyticsDropPolicy
+	// OnError is called with errors of background flushes
+	OnError func(err error)
+}
+
+// Analytics sends Impression and Engagement events in batches
+// Events are buffered and flushed in the background, call Close to flush on shutdown
+type Analytics struct {
+	Client *Client
+	Config AnalyticsConfig
+
+	lock sync.Mutex
+	// events holds the buffered *Impression and *Engagement in the order they were tracked
+	events  []interface{}
+	dropped int
+	closed  bool
+
+	trigger chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Analytics returns an Analytics client and starts its background flusher
+func (c *Client) Analytics(cfg *AnalyticsConfig) *Analytics {
+	config := AnalyticsConfig{}
+	if cfg != nil {
+		config = *cfg
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.BufferSize <= 0 {
+		config.BufferSize = 10000
+	}
+
+	a := &Analytics{
+		Client:  c,
+		Config:  config,
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go a.run()
+	return a
+}
+
+// TrackImpression buffers an Impression
+func (a *Analytics) TrackImpression(impression *Impression) error {
+	if len(impression.ContentList) == 0 {
+		return errors.New("Impression requires a ContentList")
+	}
+	if impression.UserData == nil || impression.UserData.ID == "" {
+		return errors.New("Impression requires UserData")
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return ErrAnalyticsClosed
+	}
+	if !a.makeRoom() {
+		return ErrAnalyticsBufferFull
+	}
+
+	a.events = append(a.events, impression)
+	a.notify()
+	return nil
+}
+
+// TrackEngagement buffers an Engagement
+func (a *Analytics) TrackEngagement(engagement *Engagement) error {
+	if engagement.Content == "" || engagement.Label == "" {
+		return errors.New("Engagement requires Content and Label")
+	}
+	if engagement.UserData == nil || engagement.UserData.ID == "" {
+		return errors.New("Engagement requires UserData")
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.closed {
+		return ErrAnalyticsClosed
+	}
+	if !a.makeRoom() {
+		return ErrAnalyticsBufferFull
+	}
+
+	a.events = append(a.events, engagement)
+	a.notify()
+	return nil
+}
+
+// Dropped returns the number of events dropped because the buffer was full
+func (a *Analytics) Dropped() int {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	return a.dropped
+}
+
+// Flush sends all buffered events
+// Each Impression is posted on its own, Engagements are posted in batches of BatchSize
+// Events which fail to be sent are put back in the buffer, ahead of the events tracked since,
+// and are sent again by the next Flush
+func (a *Analytics) Flush() error {
+	a.lock.Lock()
+	events := a.events
+	a.events = nil
+	a.lock.Unlock()
+
+	var firstErr error
+	var failed []interface{}
+	var engagements []*Engagement
+	sendEngagements := func() {
+		if len(engagements) == 0 {
+			return
+		}
+		payload := map[string]interface{}{"content_list": engagements}
+		if err := a.send("engagement/", payload); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			for _, engagement := range engagements {
+				failed = append(failed, engagement)
+			}
+		}
+		engagements = nil
+	}
+
+	for _, event := range events {
+		switch event := event.(type) {
+		case *Impression:
+			if err := a.send("impression/", event); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				failed = append(failed, event)
+			}
+		case *Engagement:
+			engagements = append(engagements, event)
+			if len(engagements) == a.Config.BatchSize {
+				sendEngagements()
+			}
+		}
+	}
+	sendEngagements()
+
+	if len(failed) > 0 {
+		a.requeue(failed)
+	}
+
+	return firstErr
+}
+
+// Close stops the background flusher and sends the remaining events
+// Events which fail to be sent stay buffered, Flush can be called again to retry them
+func (a *Analytics) Close() error {
+	a.lock.Lock()
+	if a.closed {
+		a.lock.Unlock()
+		return ErrAnalyticsClosed
+	}
+	a.closed = true
+	a.lock.Unlock()
+
+	close(a.stop)
+	<-a.stopped
+
+	return a.Flush()
+}
+
+// makeRoom applies the DropPolicy when the buffer is full, the lock must be held
+func (a *Analytics) makeRoom() bool {
+	if len(a.events) < a.Config.BufferSize {
+		return true
+	}
+
+	a.dropped++
+	if a.Config.DropPolicy != AnalyticsDropOldest {
+		return false
+	}
+
+	a.events = a.events[1:]
+	return true
+}
+
+// requeue puts events which failed to be sent back in front of the buffer
+// Events which no longer fit are dropped according to the DropPolicy
+func (a *Analytics) requeue(events []interface{}) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.events = append(events, a.events...)
+	if overflow := len(a.events) - a.Config.BufferSize; overflow > 0 {
+		a.dropped += overflow
+		if a.Config.DropPolicy == AnalyticsDropOldest {
+			a.events = a.events[overflow:]
+		} else {
+			a.events = a.events[:a.Config.BufferSize]
+		}
+	}
+}
+
+// notify wakes up the flusher once a batch is complete, the lock must be held
+func (a *Analytics) notify() {
+	if len(a.events) < a.Config.BatchSize {
+		return
+	}
+	select {
+	case a.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (a *Analytics) run() {
+	defer close(a.stopped)
+
+	ticker := time.NewTicker(a.Config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+		case <-a.trigger:
+		}
+
+		if err := a.Flush(); err != nil && a.Config.OnError != nil {
+			a.Config.OnError(err)
+		}
+	}
+}
+
+// send posts an Impression or a batch of Engagements to the analytics endpoint
+func (a *Analytics) send(path string, body interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	token, err := a.Client.Signer.GenerateUserScopeToken(ScopeContextAnalytics, ScopeActionAll, "*")
+	if err != nil {
+		return err
+	}
+
+	_, err = a.Client.jwtRequest(a.Client.AnalyticsURL, "POST", path, payload, nil, token)
+	return err
+}
@@ -0,0 +1,333 @@
+package getstream_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+type analyticsRequest struct {
+	Path  string
+	Query url.Values
+	Auth  string
+	Raw   string
+	Body  struct {
+		ContentList []map[string]interface{} `json:"content_list"`
+	}
+}
+
+// analyticsRecorder records the requests the analytics client sends to the test server
+type analyticsRecorder struct {
+	lock     sync.Mutex
+	recorded []*analyticsRequest
+}
+
+func (a *analyticsRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	request := &analyticsRequest{
+		Path:  r.URL.Path,
+		Query: r.URL.Query(),
+		Auth:  r.Header.Get("Authorization"),
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	request.Raw = string(body)
+	json.Unmarshal(body, &request.Body)
+
+	a.lock.Lock()
+	a.recorded = append(a.recorded, request)
+	a.lock.Unlock()
+
+	w.Write([]byte(`{}`))
+}
+
+func (a *analyticsRecorder) requests() []*analyticsRequest {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.recorded
+}
+
+func TestAnalyticsCloseFlushes(t *testing.T) {
+	recorder := &analyticsRecorder{}
+	client, server, err := PreTestSetupWithServer(recorder.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	analytics := client.Analytics(&getstream.AnalyticsConfig{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	user := &getstream.AnalyticsUser{ID: "bob"}
+	for _, content := range []string{"post:1", "post:2", "post:3"} {
+		err = analytics.TrackEngagement(&getstream.Engagement{
+			Content:  content,
+			Label:    "click",
+			FeedID:   "flat:bob",
+			Position: 1,
+			UserData: user,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = analytics.TrackImpression(&getstream.Impression{
+		ContentList: []string{"post:1", "post:2"},
+		FeedID:      "flat:bob",
+		UserData:    user,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = analytics.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engagements := 0
+	impressions := 0
+	for _, request := range recorder.requests() {
+		if request.Query.Get("api_key") != "my_key" || request.Auth == "" {
+			t.Fatal("Expected api_key and Authorization, got:", request)
+		}
+		switch request.Path {
+		case "/analytics/v1.0/engagement/":
+			if len(request.Body.ContentList) > 2 {
+				t.Fatal("Expected batches of at most 2 engagements, got:", len(request.Body.ContentList))
+			}
+			engagements += len(request.Body.ContentList)
+		case "/analytics/v1.0/impression/":
+			if request.Raw != `{"content_list":["post:1","post:2"],"feed_id":"flat:bob","user_data":{"id":"bob"}}` {
+				t.Fatal("Expected the impression to be posted on its own, got:", request.Raw)
+			}
+			impressions++
+		default:
+			t.Fatal("Unexpected path:", request.Path)
+		}
+	}
+	if engagements != 3 || impressions != 1 {
+		t.Fatal("Expected 3 engagements and 1 impression, got:", engagements, impressions)
+	}
+
+	err = analytics.TrackEngagement(&getstream.Engagement{Content: "post:1", Label: "click", UserData: user})
+	if err != getstream.ErrAnalyticsClosed {
+		t.Fatal("Expected ErrAnalyticsClosed after Close, got:", err)
+	}
+}
+
+func TestAnalyticsBackgroundFlush(t *testing.T) {
+	recorder := &analyticsRecorder{}
+	client, server, err := PreTestSetupWithServer(recorder.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	analytics := client.Analytics(&getstream.AnalyticsConfig{
+		FlushInterval: 10 * time.Millisecond,
+	})
+	defer analytics.Close()
+
+	err = analytics.TrackEngagement(&getstream.Engagement{
+		Content:  "post:1",
+		Label:    "like",
+		Score:    2,
+		UserData: &getstream.AnalyticsUser{ID: "bob", Alias: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(recorder.requests()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if len(recorder.requests()) != 1 {
+		t.Fatal("Expected the background flusher to send one request, got:", len(recorder.requests()))
+	}
+	event := recorder.requests()[0].Body.ContentList[0]
+	if event["label"] != "like" || event["score"] != 2.0 || event["user_data"].(map[string]interface{})["alias"] != "Bob" {
+		t.Fatal("Unexpected event:", event)
+	}
+}
+
+func TestAnalyticsDropPolicy(t *testing.T) {
+	recorder := &analyticsRecorder{}
+	client, server, err := PreTestSetupWithServer(recorder.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	user := &getstream.AnalyticsUser{ID: "bob"}
+
+	newest := client.Analytics(&getstream.AnalyticsConfig{
+		BufferSize:    2,
+		FlushInterval: time.Hour,
+	})
+	for i, content := range []string{"post:1", "post:2", "post:3"} {
+		err = newest.TrackEngagement(&getstream.Engagement{Content: content, Label: "click", UserData: user})
+		if i < 2 && err != nil {
+			t.Fatal(err)
+		}
+		if i == 2 && err != getstream.ErrAnalyticsBufferFull {
+			t.Fatal("Expected ErrAnalyticsBufferFull, got:", err)
+		}
+	}
+	if newest.Dropped() != 1 {
+		t.Fatal("Expected 1 dropped event, got:", newest.Dropped())
+	}
+
+	oldest := client.Analytics(&getstream.AnalyticsConfig{
+		BufferSize:    2,
+		FlushInterval: time.Hour,
+		DropPolicy:    getstream.AnalyticsDropOldest,
+	})
+	for _, content := range []string{"post:4", "post:5", "post:6"} {
+		err = oldest.TrackEngagement(&getstream.Engagement{Content: content, Label: "click", UserData: user})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if oldest.Dropped() != 1 {
+		t.Fatal("Expected 1 dropped event, got:", oldest.Dropped())
+	}
+
+	err = oldest.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := recorder.requests()
+	if len(sent) != 1 || len(sent[0].Body.ContentList) != 2 || sent[0].Body.ContentList[0]["content"] != "post:5" {
+		t.Fatal("Expected post:5 and post:6 to be sent, got:", sent)
+	}
+
+	mixed := client.Analytics(&getstream.AnalyticsConfig{
+		BufferSize:    2,
+		FlushInterval: time.Hour,
+		DropPolicy:    getstream.AnalyticsDropOldest,
+	})
+	err = mixed.TrackEngagement(&getstream.Engagement{Content: "post:7", Label: "click", UserData: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{"post:8", "post:9"} {
+		err = mixed.TrackImpression(&getstream.Impression{ContentList: []string{content}, UserData: user})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = mixed.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent = recorder.requests()[1:]
+	if len(sent) != 2 || sent[0].Path != "/analytics/v1.0/impression/" || sent[1].Path != "/analytics/v1.0/impression/" {
+		t.Fatal("Expected the oldest event, the engagement, to be dropped, got:", sent)
+	}
+
+	newest.Close()
+}
+
+func TestAnalyticsValidation(t *testing.T) {
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	analytics := client.Analytics(nil)
+	defer analytics.Close()
+
+	err = analytics.TrackEngagement(&getstream.Engagement{Content: "post:1", UserData: &getstream.AnalyticsUser{ID: "bob"}})
+	if err == nil {
+		t.Fatal("Expected an error for an Engagement without Label")
+	}
+	err = analytics.TrackImpression(&getstream.Impression{ContentList: []string{"post:1"}})
+	if err == nil {
+		t.Fatal("Expected an error for an Impression without UserData")
+	}
+}
+
+func TestAnalyticsFlushRequeuesFailedEvents(t *testing.T) {
+	recorder := &analyticsRecorder{}
+	var failing int32 = 1
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"detail": "unavailable"}`))
+			return
+		}
+		recorder.ServeHTTP(w, r)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	analytics := client.Analytics(&getstream.AnalyticsConfig{
+		BufferSize:    3,
+		FlushInterval: time.Hour,
+	})
+	defer analytics.Close()
+
+	user := &getstream.AnalyticsUser{ID: "bob"}
+	for _, content := range []string{"post:1", "post:2"} {
+		err = analytics.TrackEngagement(&getstream.Engagement{Content: content, Label: "click", UserData: user})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = analytics.TrackImpression(&getstream.Impression{ContentList: []string{"post:1"}, UserData: user})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = analytics.Flush()
+	if err == nil {
+		t.Fatal("Expected an error when the events can't be sent")
+	}
+	if analytics.Dropped() != 0 {
+		t.Fatal("Expected the failed events to be kept, got dropped:", analytics.Dropped())
+	}
+
+	// the failed events are in front, post:3 doesn't fit anymore
+	err = analytics.TrackEngagement(&getstream.Engagement{Content: "post:3", Label: "click", UserData: user})
+	if err != getstream.ErrAnalyticsBufferFull {
+		t.Fatal("Expected ErrAnalyticsBufferFull, got:", err)
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	err = analytics.Flush()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	engagements := 0
+	impressions := 0
+	for _, request := range recorder.requests() {
+		switch request.Path {
+		case "/analytics/v1.0/engagement/":
+			engagements += len(request.Body.ContentList)
+		case "/analytics/v1.0/impression/":
+			impressions++
+		}
+	}
+	if engagements != 2 || impressions != 1 {
+		t.Fatal("Expected the failed events to be sent again, got:", engagements, impressions)
+	}
+}
@@ -0,0 +1,144 @@
+package getstream
+
+import (
+	"errors"
+)
+
+// BackfillInput configures a FlatFeed Backfill
+type BackfillInput struct {
+	// PageSize is the number of Activities read per request, defaults to 100
+	PageSize int
+	// BatchSize is the number of Activities added per request, defaults to 100 (the API maximum)
+	BatchSize int
+	// Limit is the maximum number of Activities read from the source, 0 reads the whole feed
+	Limit int
+	// OnProgress is called after every batch added to the target
+	OnProgress func(progress BackfillProgress)
+}
+
+// BackfillProgress reports how far a Backfill got
+// Skipped counts Activities already present in the target, or without ForeignID
+// WithoutForeignID holds the ids of the skipped source Activities without ForeignID, they can't be
+// told apart from the Activities of the target, so they aren't copied
+type BackfillProgress struct {
+	Read             int
+	Added            int
+	Skipped          int
+	WithoutForeignID []string
+}
+
+// Backfill copies the Activities of a source FlatFeed into the current FlatFeed
+// ForeignID and TimeStamp are preserved and Activities whose ForeignID is already present
+// are skipped, so a Backfill can safely be repeated. Activities without ForeignID are skipped as well,
+// their ids are reported in BackfillProgress.WithoutForeignID
+func (f *FlatFeed) Backfill(source *FlatFeed, input *BackfillInput) (BackfillProgress, error) {
+	progress := BackfillProgress{}
+
+	options := BackfillInput{}
+	if input != nil {
+		options = *input
+	}
+	if options.PageSize <= 0 {
+		options.PageSize = 100
+	}
+	if options.BatchSize <= 0 || options.BatchSize > 100 {
+		options.BatchSize = 100
+	}
+
+	if source.FeedID() == f.FeedID() {
+		return progress, errors.New("cannot backfill a feed from itself")
+	}
+
+	present := make(map[string]bool)
+	err := f.eachActivity(options.PageSize, 0, func(activity *Activity) error {
+		if activity.ForeignID != "" {
+			present[activity.ForeignID] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return progress, err
+	}
+
+	var batch []*Activity
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		_, err := f.AddActivities(batch)
+		if err != nil {
+			return err
+		}
+		progress.Added += len(batch)
+		batch = nil
+
+		if options.OnProgress != nil {
+			options.OnProgress(progress)
+		}
+		return nil
+	}
+
+	err = source.eachActivity(options.PageSize, options.Limit, func(activity *Activity) error {
+		progress.Read++
+		if activity.ForeignID == "" {
+			progress.Skipped++
+			progress.WithoutForeignID = append(progress.WithoutForeignID, activity.ID)
+			return nil
+		}
+		if present[activity.ForeignID] {
+			progress.Skipped++
+			return nil
+		}
+		present[activity.ForeignID] = true
+
+		// copy, leaving out what belongs to the source feed
+		activityCopy := *activity
+		activityCopy.ID = ""
+		activityCopy.Origin = ""
+		activityCopy.To = nil
+		activityCopy.Score = 0
+		batch = append(batch, &activityCopy)
+
+		if len(batch) >= options.BatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return progress, err
+	}
+
+	return progress, flush()
+}
+
+// eachActivity pages through the FlatFeed, newest first, using id_lt
+// a limit of 0 reads the whole feed, an error returned by fn stops the iteration
+func (f *FlatFeed) eachActivity(pageSize int, limit int, fn func(activity *Activity) error) error {
+	input := &GetFlatFeedInput{
+		Limit: pageSize,
+	}
+
+	read := 0
+	for {
+		if limit > 0 && limit-read < input.Limit {
+			input.Limit = limit - read
+		}
+
+		output, err := f.Activities(input)
+		if err != nil {
+			return err
+		}
+
+		for _, activity := range output.Activities {
+			if err := fn(activity); err != nil {
+				return err
+			}
+		}
+		read += len(output.Activities)
+
+		if len(output.Activities) < input.Limit || (limit > 0 && read >= limit) {
+			return nil
+		}
+		input.IDLT = output.Activities[len(output.Activities)-1].ID
+	}
+}
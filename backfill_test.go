@@ -0,0 +1,117 @@
+package getstream_test
+
+import (
+	"fmt"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestFlatFeedBackfill(t *testing.T) {
+	api := newFakeAPI()
+
+	client, server, err := PreTestSetupWithServer(api.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	for i := 1; i <= 7; i++ {
+		api.add("flat:source", map[string]interface{}{
+			"actor":      "bob",
+			"verb":       "post",
+			"object":     fmt.Sprintf("post:%d", i),
+			"foreign_id": fmt.Sprintf("post:%d", i),
+			"time":       fmt.Sprintf("2017-01-0%dT10:00:00.000000", i),
+			"color":      "blue",
+		})
+	}
+	withoutForeignID := api.add("flat:source", map[string]interface{}{"actor": "bob", "verb": "like", "object": "post:0"})
+	api.add("flat:target", map[string]interface{}{"actor": "bob", "verb": "post", "object": "post:3", "foreign_id": "post:3"})
+
+	source, err := client.FlatFeed("flat", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := client.FlatFeed("flat", "target")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reported []getstream.BackfillProgress
+	progress, err := target.Backfill(source, &getstream.BackfillInput{
+		PageSize:  3,
+		BatchSize: 4,
+		OnProgress: func(progress getstream.BackfillProgress) {
+			reported = append(reported, progress)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if progress.Read != 8 || progress.Added != 6 || progress.Skipped != 2 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+	if len(progress.WithoutForeignID) != 1 || progress.WithoutForeignID[0] != withoutForeignID["id"] {
+		t.Fatal("Expected the activity without foreign id to be reported, got:", progress.WithoutForeignID)
+	}
+	if len(reported) != 2 || reported[0].Added != 4 || reported[1].Added != 6 {
+		t.Fatal("Unexpected progress reports:", reported)
+	}
+
+	stored := api.activities["flat:target"]
+	if len(stored) != 7 {
+		t.Fatal("Expected 7 activities in the target, got:", len(stored))
+	}
+	// the source is read newest first, the first added activity is post:7
+	added := stored[1]
+	if added["foreign_id"] != "post:7" || added["time"] != "2017-01-07T10:00:00" || added["color"] != "blue" {
+		t.Fatal("Unexpected backfilled activity:", added)
+	}
+
+	// repeating the backfill adds nothing
+	progress, err = target.Backfill(source, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Added != 0 || progress.Skipped != 8 {
+		t.Fatal("Expected a repeated backfill to skip everything, got:", progress)
+	}
+}
+
+func TestFlatFeedBackfillLimit(t *testing.T) {
+	api := newFakeAPI()
+
+	client, server, err := PreTestSetupWithServer(api.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	for i := 1; i <= 5; i++ {
+		api.add("flat:source", map[string]interface{}{"actor": "bob", "verb": "post", "object": "x", "foreign_id": fmt.Sprintf("post:%d", i)})
+	}
+
+	source, err := client.FlatFeed("flat", "source")
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := client.FlatFeed("flat", "target")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	progress, err := target.Backfill(source, &getstream.BackfillInput{PageSize: 2, Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Read != 3 || progress.Added != 3 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+
+	_, err = source.Backfill(source, nil)
+	if err == nil {
+		t.Fatal("Expected an error when backfilling a feed from itself")
+	}
+}
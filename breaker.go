@@ -0,0 +1,239 @@
+package getstream
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without sending the request, while a CircuitBreaker is open
+var ErrCircuitOpen = errors.New("Circuit breaker is open, the request was not sent")
+
+// CircuitState is the state of a CircuitBreaker
+type CircuitState int
+
+// Circuit states
+const (
+	// CircuitClosed sends every request
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails every request with ErrCircuitOpen
+	CircuitOpen
+	// CircuitHalfOpen sends one probe request at a time, failing the others
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker
+type CircuitBreakerConfig struct {
+	// Window is the number of latest requests the failure rate is computed over, defaults to 20
+	Window int
+	// FailureRate trips the circuit when reached over a full Window, defaults to 0.5
+	FailureRate float64
+	// ConsecutiveTimeouts trips the circuit after as many timeouts in a row, defaults to 5
+	ConsecutiveTimeouts int
+	// OpenTimeout is how long the circuit stays open before probing, defaults to 30 seconds
+	OpenTimeout time.Duration
+	// HalfOpenProbes is the number of successful probes closing the circuit again, defaults to 1
+	HalfOpenProbes int
+
+	// OnStateChange is called after every state change, from the goroutine making the request
+	OnStateChange func(from CircuitState, to CircuitState)
+}
+
+// CircuitBreaker fails requests fast while the API is failing, add its Interceptor to
+// Client.Interceptors to enable it
+// Requests fail when they get no response or a 5xx or 429 response, other API errors
+// such as 404 are successful requests as far as the CircuitBreaker is concerned
+type CircuitBreaker struct {
+	config CircuitBreakerConfig
+
+	lock     sync.Mutex
+	state    CircuitState
+	openedAt time.Time
+	// results is a ring of the latest requests, true for failures
+	results  []bool
+	next     int
+	count    int
+	failures int
+	timeouts int
+	probing  bool
+	probes   int
+}
+
+// NewCircuitBreaker returns a closed CircuitBreaker
+func NewCircuitBreaker(cfg *CircuitBreakerConfig) *CircuitBreaker {
+	config := CircuitBreakerConfig{}
+	if cfg != nil {
+		config = *cfg
+	}
+	if config.Window <= 0 {
+		config.Window = 20
+	}
+	if config.FailureRate <= 0 {
+		config.FailureRate = 0.5
+	}
+	if config.ConsecutiveTimeouts <= 0 {
+		config.ConsecutiveTimeouts = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = 1
+	}
+
+	return &CircuitBreaker{
+		config:  config,
+		results: make([]bool, config.Window),
+	}
+}
+
+// State returns the current state, an open circuit whose OpenTimeout passed is half-open
+func (b *CircuitBreaker) State() CircuitState {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// Interceptor returns the Interceptor failing requests with ErrCircuitOpen while the circuit is open
+// Place it before RetryInterceptor, so that a request failing after its retries counts once
+func (b *CircuitBreaker) Interceptor() Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			probe, err := b.allow()
+			if err != nil {
+				return nil, err
+			}
+
+			resp, err := next(req)
+
+			failed := err != nil && (resp == nil || resp.StatusCode >= 500 || resp.StatusCode == 429)
+			b.record(probe, failed, failed && isTimeout(err))
+			return resp, err
+		}
+	}
+}
+
+// allow tells whether a request may be sent, and whether it is a probe
+func (b *CircuitBreaker) allow() (bool, error) {
+	b.lock.Lock()
+
+	var changed []CircuitState
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= b.config.OpenTimeout {
+		changed = b.setState(CircuitHalfOpen)
+	}
+
+	probe := false
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			err = ErrCircuitOpen
+		} else {
+			b.probing = true
+			probe = true
+		}
+	}
+	b.lock.Unlock()
+
+	b.notify(changed)
+	return probe, err
+}
+
+// record counts the outcome of a request
+func (b *CircuitBreaker) record(probe bool, failed bool, timeout bool) {
+	b.lock.Lock()
+
+	var changed []CircuitState
+	switch {
+	case probe:
+		b.probing = false
+		b.probes++
+		if failed {
+			changed = b.setState(CircuitOpen)
+		} else if b.probes >= b.config.HalfOpenProbes {
+			changed = b.setState(CircuitClosed)
+		}
+	case b.state == CircuitClosed:
+		// requests sent while the circuit was closed, which finished after it opened, don't count
+		if b.count == len(b.results) && b.results[b.next] {
+			b.failures--
+		}
+		b.results[b.next] = failed
+		b.next = (b.next + 1) % len(b.results)
+		if b.count < len(b.results) {
+			b.count++
+		}
+		if failed {
+			b.failures++
+		}
+
+		if timeout {
+			b.timeouts++
+		} else {
+			b.timeouts = 0
+		}
+
+		if b.timeouts >= b.config.ConsecutiveTimeouts ||
+			(b.count == len(b.results) && float64(b.failures) >= b.config.FailureRate*float64(b.count)) {
+			changed = b.setState(CircuitOpen)
+		}
+	}
+	b.lock.Unlock()
+
+	b.notify(changed)
+}
+
+// setState changes the state, resetting the counters of the new state,
+// and returns the change to report once the lock is released
+func (b *CircuitBreaker) setState(state CircuitState) []CircuitState {
+	from := b.state
+	b.state = state
+	switch state {
+	case CircuitOpen:
+		b.openedAt = time.Now()
+	case CircuitHalfOpen:
+		b.probing = false
+		b.probes = 0
+	case CircuitClosed:
+		for i := range b.results {
+			b.results[i] = false
+		}
+		b.next = 0
+		b.count = 0
+		b.failures = 0
+		b.timeouts = 0
+	}
+	return []CircuitState{from, state}
+}
+
+func (b *CircuitBreaker) notify(changed []CircuitState) {
+	if changed != nil && b.config.OnStateChange != nil {
+		b.config.OnStateChange(changed[0], changed[1])
+	}
+}
+
+// isTimeout tells whether err is a timeout of the http client
+func isTimeout(err error) bool {
+	timeout, ok := err.(interface {
+		Timeout() bool
+	})
+	return ok && timeout.Timeout()
+}
@@ -0,0 +1,115 @@
+package getstream_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestCircuitBreakerFailureRate(t *testing.T) {
+	var failing int32 = 1
+	var calls int32
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code": 0, "exception": "InternalError"}`))
+			return
+		}
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var changes []string
+	breaker := getstream.NewCircuitBreaker(&getstream.CircuitBreakerConfig{
+		Window:      4,
+		FailureRate: 0.5,
+		OpenTimeout: 20 * time.Millisecond,
+		OnStateChange: func(from getstream.CircuitState, to getstream.CircuitState) {
+			changes = append(changes, from.String()+">"+to.String())
+		},
+	})
+	client.Interceptors = []getstream.Interceptor{breaker.Interceptor()}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		_, err = feed.Activities(nil)
+		if err == nil || err == getstream.ErrCircuitOpen {
+			t.Fatal("Expected the API error, got:", err)
+		}
+	}
+	if breaker.State() != getstream.CircuitOpen {
+		t.Fatal("Expected the circuit to open, got:", breaker.State())
+	}
+
+	_, err = feed.Activities(nil)
+	if err != getstream.ErrCircuitOpen || atomic.LoadInt32(&calls) != 4 {
+		t.Fatal("Expected the request to fail fast, got:", err, calls)
+	}
+
+	// a failed probe opens the circuit again
+	time.Sleep(25 * time.Millisecond)
+	_, err = feed.Activities(nil)
+	if err == nil || err == getstream.ErrCircuitOpen || breaker.State() != getstream.CircuitOpen {
+		t.Fatal("Expected the probe to fail, got:", err, breaker.State())
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	time.Sleep(25 * time.Millisecond)
+	_, err = feed.Activities(nil)
+	if err != nil || breaker.State() != getstream.CircuitClosed {
+		t.Fatal("Expected the probe to close the circuit, got:", err, breaker.State())
+	}
+
+	expected := []string{"closed>open", "open>half-open", "half-open>open", "open>half-open", "half-open>closed"}
+	if fmt.Sprint(changes) != fmt.Sprint(expected) {
+		t.Fatal("Unexpected state changes:", changes)
+	}
+}
+
+func TestCircuitBreakerTimeouts(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client.HTTP.Timeout = 10 * time.Millisecond
+	breaker := getstream.NewCircuitBreaker(&getstream.CircuitBreakerConfig{ConsecutiveTimeouts: 2})
+	client.Interceptors = []getstream.Interceptor{breaker.Interceptor()}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed.Activities(nil)
+	if breaker.State() != getstream.CircuitClosed {
+		t.Fatal("Expected a single timeout to keep the circuit closed")
+	}
+	_, err = feed.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed.Activities(nil)
+	feed.Activities(nil)
+	if breaker.State() != getstream.CircuitOpen {
+		t.Fatal("Expected consecutive timeouts to open the circuit, got:", breaker.State())
+	}
+}
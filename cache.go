@@ -0,0 +1,227 @@
+package getstream
+
+import (
+	"container/list"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheKey identifies a cached feed read, Request holds the path and the read params
+type CacheKey struct {
+	FeedID  string
+	Request string
+}
+
+// CacheStore keeps the cached feed reads of CacheInterceptor, it must be safe for concurrent use
+type CacheStore interface {
+	// Get returns the cached response body, false when it is missing or expired
+	Get(key CacheKey) ([]byte, bool)
+	Set(key CacheKey, body []byte, ttl time.Duration)
+	// Invalidate removes every cached read of the feed
+	Invalidate(feedID string)
+}
+
+// CacheInterceptor caches the activity reads of feeds (Activities and the enriched reads) in store for ttl
+// Requests made through the same Client which write to a feed invalidate its cached reads:
+// adding, removing or updating activities, following and unfollowing, as well as adding activities with
+// the feed in their To field, following with FollowMany and adding activities with AddActivityToMany
+// Notification feed reads which mark groups as read or seen are never cached and invalidate the feed,
+// as they change its counters
+// Place it before RetryInterceptor, so that cached reads aren't retried
+func CacheInterceptor(store CacheStore, ttl time.Duration) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			if req.Method != "GET" {
+				resp, err := next(req)
+				// the write may have gone through even when it failed
+				for _, feedID := range writeTargets(req) {
+					store.Invalidate(feedID)
+				}
+				return resp, err
+			}
+
+			if req.FeedID == "" {
+				return next(req)
+			}
+
+			if !strings.HasSuffix(req.Operation, ".activities") && !strings.HasSuffix(req.Operation, ".enriched_activities") {
+				return next(req)
+			}
+
+			if req.Params["mark_read"] != "" || req.Params["mark_seen"] != "" {
+				resp, err := next(req)
+				store.Invalidate(req.FeedID.Value())
+				return resp, err
+			}
+
+			key := CacheKey{FeedID: req.FeedID.Value(), Request: cacheRequest(req)}
+			if body, ok := store.Get(key); ok {
+				return &Response{StatusCode: 200, Body: body, Cached: true}, nil
+			}
+
+			resp, err := next(req)
+			if err == nil {
+				store.Set(key, resp.Body, ttl)
+			}
+			return resp, err
+		}
+	}
+}
+
+// cacheRequest is the path and the sorted query params of req
+func cacheRequest(req *Request) string {
+	query := url.Values{}
+	for key, value := range req.Params {
+		query.Set(key, value)
+	}
+	return req.Path + "?" + query.Encode()
+}
+
+// writeTargets returns the feeds a write changes: its own feed, the sources of FollowMany,
+// the feeds of AddActivityToMany and the feeds in to fields
+func writeTargets(req *Request) []string {
+	var targets []string
+	if req.FeedID != "" {
+		targets = append(targets, req.FeedID.Value())
+	}
+
+	switch req.Path {
+	case "follow_many/":
+		var follows []PostFlatFeedFollowingManyInput
+		if json.Unmarshal(req.Payload, &follows) == nil {
+			for _, follow := range follows {
+				targets = append(targets, follow.Source)
+			}
+		}
+	case "feed/add_to_many/":
+		var input struct {
+			FeedIDs []string `json:"feeds"`
+		}
+		if json.Unmarshal(req.Payload, &input) == nil {
+			targets = append(targets, input.FeedIDs...)
+		}
+	}
+
+	return append(targets, payloadTargets(req.Payload)...)
+}
+
+// payloadTargets returns the feeds in the to fields of the activities in payload, "user:bob token"
+func payloadTargets(payload []byte) []string {
+	if !strings.Contains(string(payload), `"to"`) {
+		return nil
+	}
+
+	type toActivity struct {
+		To []string `json:"to"`
+	}
+	var input struct {
+		toActivity
+		Activity   toActivity   `json:"activity"`
+		Activities []toActivity `json:"activities"`
+	}
+	if json.Unmarshal(payload, &input) != nil {
+		return nil
+	}
+
+	var targets []string
+	for _, activity := range append(input.Activities, input.toActivity, input.Activity) {
+		for _, to := range activity.To {
+			targets = append(targets, strings.SplitN(to, " ", 2)[0])
+		}
+	}
+	return targets
+}
+
+// LRUCache is an in-memory CacheStore which holds up to a number of reads, evicting the least recently used
+type LRUCache struct {
+	size int
+
+	lock    sync.Mutex
+	entries *list.List
+	keys    map[CacheKey]*list.Element
+	feeds   map[string]map[CacheKey]bool
+}
+
+type lruEntry struct {
+	key     CacheKey
+	body    []byte
+	expires time.Time
+}
+
+// NewLRUCache returns an LRUCache holding up to size reads
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:    size,
+		entries: list.New(),
+		keys:    map[CacheKey]*list.Element{},
+		feeds:   map[string]map[CacheKey]bool{},
+	}
+}
+
+// Get returns the cached response body, false when it is missing or expired
+func (c *LRUCache) Get(key CacheKey) ([]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	element, ok := c.keys[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+	c.entries.MoveToFront(element)
+	return entry.body, true
+}
+
+// Set caches body for ttl
+func (c *LRUCache) Set(key CacheKey, body []byte, ttl time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if element, ok := c.keys[key]; ok {
+		c.remove(element)
+	}
+	c.keys[key] = c.entries.PushFront(&lruEntry{key: key, body: body, expires: time.Now().Add(ttl)})
+	if c.feeds[key.FeedID] == nil {
+		c.feeds[key.FeedID] = map[CacheKey]bool{}
+	}
+	c.feeds[key.FeedID][key] = true
+
+	for c.entries.Len() > c.size {
+		c.remove(c.entries.Back())
+	}
+}
+
+// Invalidate removes every cached read of the feed
+func (c *LRUCache) Invalidate(feedID string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for key := range c.feeds[feedID] {
+		c.remove(c.keys[key])
+	}
+}
+
+// Len returns the number of cached reads, expired ones included
+func (c *LRUCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.entries.Len()
+}
+
+func (c *LRUCache) remove(element *list.Element) {
+	entry := element.Value.(*lruEntry)
+	c.entries.Remove(element)
+	delete(c.keys, entry.key)
+	delete(c.feeds[entry.key.FeedID], entry.key)
+	if len(c.feeds[entry.key.FeedID]) == 0 {
+		delete(c.feeds, entry.key.FeedID)
+	}
+}
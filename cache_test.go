@@ -0,0 +1,230 @@
+package getstream_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestCacheInterceptor(t *testing.T) {
+	api := newFakeAPI()
+	client, server, err := PreTestSetupWithServer(api.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cache := getstream.NewLRUCache(10)
+	client.Interceptors = []getstream.Interceptor{getstream.CacheInterceptor(cache, time.Minute)}
+
+	bob, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := client.FlatFeed("user", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api.add("user:bob", map[string]interface{}{"actor": "bob", "verb": "post", "object": "post:1"})
+
+	reads := func() int {
+		api.Lock()
+		defer api.Unlock()
+		count := 0
+		for _, request := range api.requests {
+			if request[:4] == "GET " {
+				count++
+			}
+		}
+		return count
+	}
+
+	for i := 0; i < 3; i++ {
+		output, err := bob.Activities(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(output.Activities) != 1 {
+			t.Fatal("Unexpected activities:", output.Activities)
+		}
+	}
+	if reads() != 1 {
+		t.Fatal("Expected a single read, got:", reads())
+	}
+
+	_, err = bob.Activities(&getstream.GetFlatFeedInput{Limit: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reads() != 2 || cache.Len() != 2 {
+		t.Fatal("Expected reads with other params to be cached apart, got:", reads(), cache.Len())
+	}
+
+	_, err = alice.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = bob.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:2", To: []getstream.Feed{alice}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("Expected the writes to bob and alice to invalidate their reads, got:", cache.Len())
+	}
+
+	output, err := bob.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Activities) != 2 || reads() != 4 {
+		t.Fatal("Expected the feed to be read again, got:", output.Activities, reads())
+	}
+
+	err = bob.RemoveActivity(output.Activities[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cache.Len() != 0 {
+		t.Fatal("Expected the removal to invalidate the reads, got:", cache.Len())
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := getstream.NewLRUCache(2)
+	first := getstream.CacheKey{FeedID: "user:bob", Request: "feed/user/bob/?"}
+	second := getstream.CacheKey{FeedID: "user:bob", Request: "feed/user/bob/?limit=5"}
+	third := getstream.CacheKey{FeedID: "user:alice", Request: "feed/user/alice/?"}
+
+	cache.Set(first, []byte("1"), time.Minute)
+	cache.Set(second, []byte("2"), time.Minute)
+	cache.Get(first)
+	cache.Set(third, []byte("3"), time.Minute)
+
+	if _, ok := cache.Get(second); ok {
+		t.Fatal("Expected the least recently used read to be evicted")
+	}
+	if body, ok := cache.Get(first); !ok || string(body) != "1" {
+		t.Fatal("Unexpected read:", string(body), ok)
+	}
+
+	cache.Invalidate("user:bob")
+	if _, ok := cache.Get(first); ok || cache.Len() != 1 {
+		t.Fatal("Expected the reads of user:bob to be invalidated")
+	}
+
+	cache.Set(first, []byte("1"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := cache.Get(first); ok {
+		t.Fatal("Expected the read to expire")
+	}
+}
+
+func TestCacheInterceptorMarkRead(t *testing.T) {
+	var lock sync.Mutex
+	unread := 3
+	marks := 0
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if r.URL.Query().Get("mark_read") != "" {
+			marks++
+			unread = 0
+		}
+		fmt.Fprintf(w, `{"results": [], "unread": %d, "unseen": 0}`, unread)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cache := getstream.NewLRUCache(10)
+	client.Interceptors = []getstream.Interceptor{getstream.CacheInterceptor(cache, time.Minute)}
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts, err := feed.Counts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Unread != 3 {
+		t.Fatal("Unexpected counts:", counts)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err = feed.MarkAllRead()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if marks != 2 {
+		t.Fatal("Expected every mark to reach the API, got:", marks)
+	}
+
+	counts, err = feed.Counts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Unread != 0 {
+		t.Fatal("Expected the counts to change after marking, got:", counts)
+	}
+}
+
+func TestCacheInterceptorManyFeeds(t *testing.T) {
+	var lock sync.Mutex
+	reads := map[string]int{}
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if r.Method == "GET" {
+			reads[r.URL.Path]++
+		}
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client.Interceptors = []getstream.Interceptor{getstream.CacheInterceptor(getstream.NewLRUCache(10), time.Minute)}
+
+	bob, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := client.FlatFeed("user", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readBoth := func() {
+		for _, feed := range []*getstream.FlatFeed{bob, alice} {
+			if _, err := feed.Activities(nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	readBoth()
+	err = client.FollowMany([]getstream.PostFlatFeedFollowingManyInput{{Source: "user:bob", Target: "user:carol"}}, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	readBoth()
+	if reads["/api/v1.0/feed/user/bob/"] != 2 || reads["/api/v1.0/feed/user/alice/"] != 1 {
+		t.Fatal("Expected FollowMany to invalidate the reads of its sources, got:", reads)
+	}
+
+	err = client.AddActivityToMany(getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"}, []string{"user:alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	readBoth()
+	if reads["/api/v1.0/feed/user/bob/"] != 2 || reads["/api/v1.0/feed/user/alice/"] != 2 {
+		t.Fatal("Expected AddActivityToMany to invalidate the reads of its feeds, got:", reads)
+	}
+}
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,61 +15,72 @@ import (
 
 // Client is used to connect to getstream.io
 type Client struct {
-	HTTP    *http.Client
-	BaseURL *url.URL // https://api.getstream.io/api/
-	Config  *Config
-	Signer  *Signer
+	HTTP               *http.Client
+	BaseURL            *url.URL // https://api.getstream.io/api/
+	RealtimeURL        *url.URL // wss://faye.getstream.io/faye
+	AnalyticsURL       *url.URL // https://analytics.stream-io-api.com/analytics/v1.0/
+	PersonalizationURL *url.URL // https://personalization.stream-io-api.com/personalization/v1.0/
+	Config             *Config
+	Signer             *Signer
+
+	// Logger receives every request and response, LogBodies includes their bodies
+	Logger    Logger
+	LogBodies bool
+
+	// Interceptors wrap every request, the first one is the outermost
+	Interceptors []Interceptor
 }
 
 // New returns a GetStream client.
 //
 // Params:
-//   cfg, pointer to a Config structure which takes the API credentials, Location, etc
+//   cfg, pointer to a Config structure which takes the API credentials, Location, etc,
+//   only its credentials are checked, LoadConfigFromEnv and LoadConfigFromFile check the rest
 // Returns:
 //   Client struct
 func New(cfg *Config) (*Client, error) {
-	var (
-		timeout int64
-	)
+	// the Client keeps its own copy, the Config it is given is left as it is
+	config := *cfg
+	cfg = &config
 
-	if cfg.APIKey == "" {
-		return nil, errors.New("Required API Key was not set")
-	}
-
-	if cfg.APISecret == "" && cfg.Token == "" {
-		return nil, errors.New("API Secret or Token was not set, one or the other is required")
+	err := cfg.validateCredentials()
+	if err != nil {
+		return nil, err
 	}
 
-	if cfg.TimeoutInt <= 0 {
-		timeout = 3
-	} else {
-		timeout = cfg.TimeoutInt
+	// TimeoutDuration takes precedence, it allows timeouts of less than a second
+	if cfg.TimeoutDuration <= 0 {
+		if cfg.TimeoutInt <= 0 {
+			cfg.SetTimeout(3)
+		} else {
+			cfg.SetTimeout(cfg.TimeoutInt)
+		}
 	}
-	cfg.SetTimeout(timeout)
 
 	if cfg.Version == "" {
 		cfg.Version = "v1.0"
 	}
 
-	location := "api"
-	port := ""
-	secure := "s"
-	if cfg.Location != "" {
-		location = cfg.Location + "-api"
-		if cfg.Location == "qa" {
-			secure = ""
-		}
-		if cfg.Location == "localhost" {
-			port = ":8000"
-			secure = ""
-		}
+	baseURL, err := locationURL(cfg.Location, cfg.Version)
+	if err != nil {
+		return nil, err
 	}
+	cfg.SetBaseURL(baseURL)
 
-	baseURL, err := url.Parse("http" + secure + "://" + location + ".getstream.io" + port + "/api/" + cfg.Version + "/")
+	realtimeURL, err := url.Parse("wss://faye.getstream.io/faye")
+	if err != nil {
+		return nil, err
+	}
+
+	analyticsURL, err := url.Parse("https://analytics.stream-io-api.com/analytics/v1.0/")
+	if err != nil {
+		return nil, err
+	}
+
+	personalizationURL, err := url.Parse("https://personalization.stream-io-api.com/personalization/v1.0/")
 	if err != nil {
 		return nil, err
 	}
-	cfg.SetBaseURL(baseURL)
 
 	var signer *Signer
 	if cfg.Token != "" {
@@ -91,14 +102,36 @@ func New(cfg *Config) (*Client, error) {
 			Transport: GETSTREAM_TRANSPORT,
 			Timeout:   cfg.TimeoutDuration,
 		},
-		BaseURL: baseURL,
-		Config:  cfg,
-		Signer:  signer,
+		BaseURL:            baseURL,
+		RealtimeURL:        realtimeURL,
+		AnalyticsURL:       analyticsURL,
+		PersonalizationURL: personalizationURL,
+		Config:             cfg,
+		Signer:             signer,
 	}
 
 	return client, nil
 }
 
+// locationURL returns the base URL of the feed API in the location, the default endpoint when it is empty
+func locationURL(location string, version string) (*url.URL, error) {
+	host := "api"
+	port := ""
+	secure := "s"
+	if location != "" {
+		host = location + "-api"
+		if location == "qa" {
+			secure = ""
+		}
+		if location == "localhost" {
+			port = ":8000"
+			secure = ""
+		}
+	}
+
+	return url.Parse("http" + secure + "://" + host + ".getstream.io" + port + "/api/" + version + "/")
+}
+
 // FlatFeed returns a getstream feed
 // Slug is the FlatFeed Group name
 // id is the Specific FlatFeed inside a FlatFeed Group
@@ -184,8 +217,6 @@ func (c *Client) AbsoluteURL(path string) (*url.URL, error) {
 		return nil, err
 	}
 
-	// DEBUG: Use this line to send stuff to a proxy instead.
-	// c.baseURL, _ = url.Parse("http://0.0.0.0:8000/")
 	result = c.BaseURL.ResolveReference(result)
 
 	qs := result.Query()
@@ -226,48 +257,29 @@ func (c *Client) del(f Feed, path string, payload []byte, params map[string]stri
 
 // request helper
 func (c *Client) request(f Feed, method string, path string, payload []byte, params map[string]string) ([]byte, error) {
-	apiURL, err := url.Parse(path)
-	if err != nil {
-		return nil, err
-	}
-
-	apiURL = c.BaseURL.ResolveReference(apiURL)
-
-	query := apiURL.Query()
-	query = c.setStandardParams(query)
-	query = c.setRequestParams(query, params)
-	apiURL.RawQuery = query.Encode()
-
-	// create a new http request
-	req, err := http.NewRequest(method, apiURL.String(), bytes.NewBuffer(payload))
+	_, err := url.Parse(path)
 	if err != nil {
 		return nil, err
 	}
 
-	// set the Auth headers for the http request
-	c.setBaseHeaders(req)
-
-	var auth string
-	var sig string
+	var auth AuthKind
 	switch {
 	case path == "follow_many/": // one feed follows many feeds
-		auth = "app"
-		sig = "sig"
+		auth = AuthApp
 	case path == "activities/": // batch activities methods
 		// feed auth
-		auth = "feed"
-		sig = "jwt"
+		auth = AuthFeedJWT
+	case path == "stats/follow/": // follower and following counts
+		// feed auth
+		auth = AuthFeedJWT
 	case path == "feed/add_to_many/": // add activity to many feeds
 		// application auth
-		auth = "app"
-		sig = "sig"
+		auth = AuthApp
 	case path[:5] == "feed": // add activity to many feeds
 		// feed auth
-		auth = "feed"
-		sig = "jwt"
+		auth = AuthFeedJWT
 	default: // everything else sig/httpsig and feed auth
-		auth = "feed"
-		sig = "sig"
+		auth = AuthFeedSignature
 	}
 
 	// fallback: if we were going to use jwt and we don't have a client token, use regular sig instead
@@ -281,33 +293,111 @@ func (c *Client) request(f Feed, method string, path string, payload []byte, par
 	//	}
 	//}
 
-	c.setAuthSigAndHeaders(req, f, auth, sig, path)
+	call := &Request{
+		Operation: feedOperation(f, method, path, payload, params),
+		Feed:      f,
+		Auth:      auth,
+		Method:    method,
+		BaseURL:   c.BaseURL,
+		Path:      path,
+		Params:    params,
+		Payload:   payload,
+		Header:    http.Header{},
+	}
+	if f != nil {
+		call.FeedID = f.FeedID()
+		call.FeedGroup = strings.Split(f.FeedID().Value(), ":")[0]
+	}
+
+	return c.do(call)
+}
+
+// do sends a prepared request through the Interceptors
+func (c *Client) do(req *Request) ([]byte, error) {
+	roundTrip := RoundTrip(c.roundTrip)
+	for i := len(c.Interceptors) - 1; i >= 0; i-- {
+		roundTrip = c.Interceptors[i](roundTrip)
+	}
 
-	// perform the http request
-	resp, err := c.HTTP.Do(req)
+	resp, err := roundTrip(req)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	return resp.Body, nil
+}
+
+// jwtRequest performs a request against one of the APIs next to the feed API (analytics, personalization)
+// which are authenticated with a JWT token
+func (c *Client) jwtRequest(baseURL *url.URL, method string, path string, payload []byte, params map[string]string, token string) ([]byte, error) {
+	call := &Request{
+		Auth:    AuthJWT,
+		Token:   token,
+		Method:  method,
+		BaseURL: baseURL,
+		Path:    path,
+		Params:  params,
+		Payload: payload,
+		Header:  http.Header{},
+	}
+	switch baseURL {
+	case c.AnalyticsURL:
+		call.Operation = "analytics." + strings.ToLower(method) + "_" + strings.Replace(strings.Trim(path, "/"), "/", "_", -1)
+	case c.PersonalizationURL:
+		call.Operation = "personalization." + strings.ToLower(method) + "_" + strings.Replace(strings.Trim(path, "/"), "/", "_", -1)
+	default:
+		call.Operation = feedOperation(nil, method, path, payload, params)
+	}
+
+	return c.do(call)
+}
 
-	// read the response
-	body, err := ioutil.ReadAll(resp.Body)
+// newHTTPRequest builds and authenticates the http request for req
+func (c *Client) newHTTPRequest(req *Request) (*http.Request, error) {
+	apiURL, err := url.Parse(req.Path)
 	if err != nil {
 		return nil, err
 	}
 
-	// handle the response
-	switch {
-	case resp.StatusCode/100 == 2: // SUCCESS
-		return body, nil
+	apiURL = req.BaseURL.ResolveReference(apiURL)
+
+	query := apiURL.Query()
+	if req.Auth == AuthJWT {
+		query.Set("api_key", c.Config.APIKey)
+	} else {
+		query = c.setStandardParams(query)
+	}
+	query = c.setRequestParams(query, req.Params)
+	apiURL.RawQuery = query.Encode()
+
+	// create a new http request
+	httpReq, err := http.NewRequest(req.Method, apiURL.String(), bytes.NewBuffer(req.Payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Cancel = req.Cancel
+
+	// set the Auth headers for the http request
+	c.setBaseHeaders(httpReq)
+	for key, values := range req.Header {
+		httpReq.Header[key] = append([]string{}, values...)
+	}
+
+	switch req.Auth {
+	case AuthJWT:
+		httpReq.Header.Set("stream-auth-type", "jwt")
+		httpReq.Header.Set("Authorization", req.Token)
+	case AuthFeedJWT:
+		err = c.setAuthSigAndHeaders(httpReq, req.Feed, "feed", "jwt", req.Path)
+	case AuthApp:
+		err = c.setAuthSigAndHeaders(httpReq, req.Feed, "app", "sig", req.Path)
 	default:
-		var respErr Error
-		err = json.Unmarshal(body, &respErr)
-		if err != nil {
-			return nil, err
-		}
-		return nil, &respErr
+		err = c.setAuthSigAndHeaders(httpReq, req.Feed, "feed", "sig", req.Path)
+	}
+	if err != nil {
+		return nil, err
 	}
+
+	return httpReq, nil
 }
 
 func (c *Client) setStandardParams(query url.Values) url.Values {
@@ -352,6 +442,12 @@ func (c *Client) setAuthSigAndHeaders(request *http.Request, f Feed, auth string
 					return err
 				}
 				request.Header.Set("Authorization", token)
+			} else if path == "stats/follow/" {
+				token, err := c.Signer.GenerateFeedScopeToken(ScopeContextFollower, ScopeActionRead, "*")
+				if err != nil {
+					return err
+				}
+				request.Header.Set("Authorization", token)
 			} else {
 				request.Header.Set("Authorization", f.Token())
 			}
@@ -428,3 +524,24 @@ func (c *Client) AddActivityToMany(activity Activity, feeds []string) error {
 	_, err = c.post(nil, endpoint, final_payload, params)
 	return err
 }
+
+// FollowMany creates many follow relationships in a single request, the feeds
+// don't need to share a Source. copyLimit is the number of Activities copied from
+// each Target, a negative copyLimit uses the default of 100
+func (c *Client) FollowMany(follows []PostFlatFeedFollowingManyInput, copyLimit int) error {
+	payload, err := json.Marshal(follows)
+	if err != nil {
+		return err
+	}
+
+	if copyLimit < 0 {
+		copyLimit = 100
+	}
+	params := map[string]string{
+		"activity_copy_limit": strconv.Itoa(copyLimit),
+	}
+
+	endpoint := "follow_many/"
+	_, err = c.post(nil, endpoint, payload, params)
+	return err
+}
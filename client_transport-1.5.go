@@ -4,7 +4,12 @@ package getstream
 
 import "net/http"
 
-var GETSTREAM_TRANSPORT = &http.Transport{
-	MaxIdleConnsPerHost: 5,
-	DisableKeepAlives:   false,
+var GETSTREAM_TRANSPORT = newTransport()
+
+// newTransport returns a transport with the settings of GETSTREAM_TRANSPORT
+func newTransport() *http.Transport {
+	return &http.Transport{
+		MaxIdleConnsPerHost: 5,
+		DisableKeepAlives:   false,
+	}
 }
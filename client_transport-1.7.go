@@ -4,9 +4,14 @@ package getstream
 
 import "net/http"
 
-var GETSTREAM_TRANSPORT = &http.Transport{
-	MaxIdleConns:        5,
-	MaxIdleConnsPerHost: 5,
-	IdleConnTimeout:     60,
-	DisableKeepAlives:   false,
+var GETSTREAM_TRANSPORT = newTransport()
+
+// newTransport returns a transport with the settings of GETSTREAM_TRANSPORT
+func newTransport() *http.Transport {
+	return &http.Transport{
+		MaxIdleConns:        5,
+		MaxIdleConnsPerHost: 5,
+		IdleConnTimeout:     60,
+		DisableKeepAlives:   false,
+	}
 }
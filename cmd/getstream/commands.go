@@ -0,0 +1,373 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+var scopeContexts = map[string]getstream.ScopeContext{
+	"activities":      getstream.ScopeContextActivities,
+	"feed":            getstream.ScopeContextFeed,
+	"follower":        getstream.ScopeContextFollower,
+	"*":               getstream.ScopeContextAll,
+	"analytics":       getstream.ScopeContextAnalytics,
+	"personalization": getstream.ScopeContextPersonalization,
+	"users":           getstream.ScopeContextUsers,
+}
+
+var scopeActions = map[string]getstream.ScopeAction{
+	"read":   getstream.ScopeActionRead,
+	"write":  getstream.ScopeActionWrite,
+	"delete": getstream.ScopeActionDelete,
+	"*":      getstream.ScopeActionAll,
+}
+
+func (c *cli) flags(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	return flags
+}
+
+// print writes value as indented JSON
+func (c *cli) print(value interface{}) error {
+	payload, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = c.stdout.Write(append(payload, '\n'))
+	return err
+}
+
+// splitFeedID splits "slug:user_id"
+func splitFeedID(feedID string) (string, string, error) {
+	parts := strings.SplitN(feedID, ":", 2)
+	if len(parts) != 2 {
+		return "", "", usageError("invalid feed " + feedID + ", expected feed_slug:user_id")
+	}
+	return parts[0], parts[1], nil
+}
+
+// flatFeed returns a FlatFeed for feedID, the endpoints used by follow and remove
+// are the same for every type of feed
+func (c *cli) flatFeed(client *getstream.Client, feedID string) (*getstream.FlatFeed, error) {
+	feedSlug, userID, err := splitFeedID(feedID)
+	if err != nil {
+		return nil, err
+	}
+	return client.FlatFeed(feedSlug, userID)
+}
+
+func (c *cli) read(args []string) error {
+	flags := c.flags("read")
+	feedType := flags.String("type", "flat", "flat, aggregated or notification")
+	limit := flags.Int("limit", 25, "number of results")
+	offset := flags.Int("offset", 0, "number of results to skip")
+	idLT := flags.String("id-lt", "", "only results older than this id")
+	ranking := flags.String("ranking", "", "ranking method")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("read takes a single feed")
+	}
+	feedSlug, userID, err := splitFeedID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	switch *feedType {
+	case "flat":
+		feed, err := client.FlatFeed(feedSlug, userID)
+		if err != nil {
+			return err
+		}
+		output, err := feed.Activities(&getstream.GetFlatFeedInput{
+			Limit:   *limit,
+			Offset:  *offset,
+			IDLT:    *idLT,
+			Ranking: *ranking,
+		})
+		if err != nil {
+			return err
+		}
+		return c.print(output)
+
+	case "aggregated":
+		feed, err := client.AggregatedFeed(feedSlug, userID)
+		if err != nil {
+			return err
+		}
+		output, err := feed.Activities(&getstream.GetAggregatedFeedInput{
+			Limit:   *limit,
+			Offset:  *offset,
+			IDLT:    *idLT,
+			Ranking: *ranking,
+		})
+		if err != nil {
+			return err
+		}
+		return c.print(output)
+
+	case "notification":
+		feed, err := client.NotificationFeed(feedSlug, userID)
+		if err != nil {
+			return err
+		}
+		output, err := feed.Activities(&getstream.GetNotificationFeedInput{
+			Limit:   *limit,
+			Offset:  *offset,
+			IDLT:    *idLT,
+			Ranking: *ranking,
+		})
+		if err != nil {
+			return err
+		}
+		return c.print(output)
+	}
+
+	return usageError("unknown feed type " + *feedType)
+}
+
+func (c *cli) add(args []string) error {
+	flags := c.flags("add")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return usageError("add takes a feed and an optional file")
+	}
+
+	var input io.Reader = c.stdin
+	if flags.NArg() == 2 {
+		file, err := os.Open(flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+	payload, err := ioutil.ReadAll(input)
+	if err != nil {
+		return err
+	}
+
+	var activities []*getstream.Activity
+	if strings.HasPrefix(strings.TrimSpace(string(payload)), "[") {
+		err = json.Unmarshal(payload, &activities)
+	} else {
+		activity := &getstream.Activity{}
+		err = json.Unmarshal(payload, activity)
+		activities = append(activities, activity)
+	}
+	if err != nil {
+		return err
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	feed, err := c.flatFeed(client, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if len(activities) == 1 {
+		activity, err := feed.AddActivity(activities[0])
+		if err != nil {
+			return err
+		}
+		return c.print(activity)
+	}
+
+	added, err := feed.AddActivities(activities)
+	if err != nil {
+		return err
+	}
+	return c.print(added)
+}
+
+func (c *cli) remove(args []string) error {
+	flags := c.flags("remove")
+	foreignID := flags.Bool("foreign-id", false, "remove by foreign id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError("remove takes a feed and an activity id")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	feed, err := c.flatFeed(client, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *foreignID {
+		return feed.RemoveActivityByForeignID(&getstream.Activity{ForeignID: flags.Arg(1)})
+	}
+	return feed.RemoveActivity(&getstream.Activity{ID: flags.Arg(1)})
+}
+
+func (c *cli) follow(args []string) error {
+	flags := c.flags("follow")
+	copyLimit := flags.Int("copy-limit", 100, "number of activities copied from the target")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError("follow takes a feed and a target feed")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	feed, err := c.flatFeed(client, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	target, err := c.flatFeed(client, flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	return feed.FollowFeedWithCopyLimit(target, *copyLimit)
+}
+
+func (c *cli) unfollow(args []string) error {
+	flags := c.flags("unfollow")
+	keepHistory := flags.Bool("keep-history", false, "keep the activities of the target")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return usageError("unfollow takes a feed and a target feed")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	feed, err := c.flatFeed(client, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	target, err := c.flatFeed(client, flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if *keepHistory {
+		return feed.UnfollowKeepingHistory(target)
+	}
+	return feed.Unfollow(target)
+}
+
+func (c *cli) followers(args []string) error {
+	return c.follows("followers", args)
+}
+
+func (c *cli) following(args []string) error {
+	return c.follows("following", args)
+}
+
+// follows lists the followers or followed feeds of a feed
+func (c *cli) follows(name string, args []string) error {
+	flags := c.flags(name)
+	limit := flags.Int("limit", 25, "number of feeds")
+	offset := flags.Int("offset", 0, "number of feeds to skip")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError(name + " takes a single feed")
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+	feed, err := c.flatFeed(client, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var feeds []*getstream.GeneralFeed
+	if name == "followers" {
+		feeds, err = feed.FollowersWithLimitAndSkip(*limit, *offset)
+	} else {
+		feeds, err = feed.FollowingWithLimitAndSkip(*limit, *offset)
+	}
+	if err != nil {
+		return err
+	}
+
+	feedIDs := []string{}
+	for _, feed := range feeds {
+		feedIDs = append(feedIDs, feed.FeedID().Value())
+	}
+	return c.print(feedIDs)
+}
+
+func (c *cli) token(args []string) error {
+	flags := c.flags("token")
+	resource := flags.String("resource", "*", "activities, feed, follower, analytics, personalization, users or *")
+	action := flags.String("action", "*", "read, write, delete or *")
+	user := flags.Bool("user", false, "mint a user scope token, the argument is a user id")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("token takes a single feed or user id")
+	}
+
+	context, ok := scopeContexts[*resource]
+	if !ok {
+		return usageError("unknown resource " + *resource)
+	}
+	scopeAction, ok := scopeActions[*action]
+	if !ok {
+		return usageError("unknown action " + *action)
+	}
+
+	client, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	var token string
+	if *user {
+		token, err = client.Signer.GenerateUserScopeToken(context, scopeAction, flags.Arg(0))
+	} else {
+		feedID := flags.Arg(0)
+		if feedID != "*" {
+			feedSlug, userID, err := splitFeedID(feedID)
+			if err != nil {
+				return err
+			}
+			feedID = feedSlug + userID
+		}
+		token, err = client.Signer.GenerateFeedScopeToken(context, scopeAction, feedID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(c.stdout, token+"\n")
+	return err
+}
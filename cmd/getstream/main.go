@@ -0,0 +1,165 @@
+// Command getstream operates on the feeds of a GetStream.io application, for debugging
+//
+// Credentials are read from STREAM_API_KEY, STREAM_API_SECRET, STREAM_APP_ID and,
+// optionally, STREAM_REGION, the same variables the tests use, see getstream.LoadConfigFromEnv
+//
+//	getstream read -type notification -limit 10 notification:bob
+//	getstream add user:bob activity.json
+//	getstream remove user:bob 8a1d0b8e-...
+//	getstream follow timeline:bob user:alice
+//	getstream unfollow -keep-history timeline:bob user:alice
+//	getstream followers user:alice
+//	getstream following timeline:bob
+//	getstream token -resource feed -action read user:bob
+//	getstream -debug read user:bob
+//
+// Results are printed as JSON, -debug prints every request and response to stderr
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"os"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+const usage = `usage: getstream [-debug] command [flags] [args]
+
+commands:
+  read [-type flat|aggregated|notification] [-limit n] [-offset n] [-id-lt id] [-ranking name] feed
+  add feed [file]                    add an activity, or an array of activities, read as JSON from file or stdin
+  remove [-foreign-id] feed id       remove an activity by id, or by foreign id
+  follow [-copy-limit n] feed target
+  unfollow [-keep-history] feed target
+  followers [-limit n] [-offset n] feed
+  following [-limit n] [-offset n] feed
+  token [-resource name] [-action name] [-user] feed|user_id
+`
+
+// cli runs commands, the client is created lazily so usage errors don't need credentials
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	newClient func() (*getstream.Client, error)
+	debug     bool
+}
+
+func main() {
+	c := &cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		newClient: func() (*getstream.Client, error) {
+			cfg, err := getstream.LoadConfigFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			return getstream.New(cfg)
+		},
+	}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+// run executes the command in args and returns the exit code
+func (c *cli) run(args []string) int {
+	flags := flag.NewFlagSet("getstream", flag.ContinueOnError)
+	flags.SetOutput(c.stderr)
+	flags.Usage = func() { fmt.Fprint(c.stderr, usage) }
+	flags.BoolVar(&c.debug, "debug", false, "print requests and responses to stderr")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	commands := map[string]func(args []string) error{
+		"read":      c.read,
+		"add":       c.add,
+		"remove":    c.remove,
+		"follow":    c.follow,
+		"unfollow":  c.unfollow,
+		"followers": c.followers,
+		"following": c.following,
+		"token":     c.token,
+	}
+	command, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintln(c.stderr, "unknown command", flags.Arg(0))
+		flags.Usage()
+		return 2
+	}
+
+	err := command(flags.Args()[1:])
+	if err == flag.ErrHelp {
+		return 2
+	}
+	if _, ok := err.(usageError); ok {
+		fmt.Fprintln(c.stderr, err)
+		flags.Usage()
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// usageError is returned for invalid arguments
+type usageError string
+
+func (e usageError) Error() string {
+	return string(e)
+}
+
+// client returns the Client, which prints requests and responses in debug mode
+func (c *cli) client() (*getstream.Client, error) {
+	client, err := c.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.debug {
+		client.HTTP.Transport = &debugTransport{
+			next: client.HTTP.Transport,
+			out:  c.stderr,
+		}
+	}
+	return client, nil
+}
+
+// debugTransport prints raw requests and responses
+type debugTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	dump, err := httputil.DumpRequestOut(req, true)
+	if err == nil {
+		fmt.Fprintf(t.out, "%s\n\n", dump)
+	}
+
+	next := t.next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	dump, err = httputil.DumpResponse(resp, true)
+	if err == nil {
+		fmt.Fprintf(t.out, "%s\n\n", dump)
+	}
+	return resp, nil
+}
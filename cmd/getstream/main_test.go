@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func testCLI(t *testing.T, handler http.HandlerFunc) (*cli, *bytes.Buffer, *bytes.Buffer, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	c := &cli{
+		stdin:  strings.NewReader(""),
+		stdout: stdout,
+		stderr: stderr,
+		newClient: func() (*getstream.Client, error) {
+			client, err := getstream.New(&getstream.Config{
+				APIKey:    "my_key",
+				APISecret: "my_secret",
+				AppID:     "111111",
+			})
+			if err != nil {
+				return nil, err
+			}
+			client.BaseURL, err = url.Parse(server.URL + "/api/v1.0/")
+			return client, err
+		},
+	}
+	return c, stdout, stderr, server
+}
+
+func TestRead(t *testing.T) {
+	var request *http.Request
+	c, stdout, _, server := testCLI(t, func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		w.Write([]byte(`{"results": [{"id": "1", "actor": "bob", "verb": "post", "object": "post:1", "time": "2017-01-01T10:00:00"}]}`))
+	})
+	defer server.Close()
+
+	code := c.run([]string{"read", "-limit", "5", "-id-lt", "9", "user:bob"})
+	if code != 0 {
+		t.Fatal("Unexpected exit code:", code)
+	}
+	if request.URL.Path != "/api/v1.0/feed/user/bob/" || request.URL.Query().Get("limit") != "5" || request.URL.Query().Get("id_lt") != "9" {
+		t.Fatal("Unexpected request:", request.URL)
+	}
+	if !strings.Contains(stdout.String(), `"object": "post:1"`) {
+		t.Fatal("Unexpected output:", stdout.String())
+	}
+}
+
+func TestAddAndFollow(t *testing.T) {
+	var requests []string
+	c, _, _, server := testCLI(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"activities": [], "id": "1"}`))
+	})
+	defer server.Close()
+
+	c.stdin = strings.NewReader(`[{"actor": "bob", "verb": "post", "object": "post:1"}, {"actor": "bob", "verb": "post", "object": "post:2"}]`)
+	if code := c.run([]string{"add", "user:bob"}); code != 0 {
+		t.Fatal("Unexpected exit code:", code)
+	}
+	if code := c.run([]string{"follow", "-copy-limit", "0", "timeline:bob", "user:alice"}); code != 0 {
+		t.Fatal("Unexpected exit code:", code)
+	}
+	if code := c.run([]string{"unfollow", "timeline:bob", "user:alice"}); code != 0 {
+		t.Fatal("Unexpected exit code:", code)
+	}
+
+	if len(requests) != 3 {
+		t.Fatal("Unexpected requests:", requests)
+	}
+	if !strings.HasPrefix(requests[0], `POST /api/v1.0/feed/user/bob/ {"activities":[`) {
+		t.Fatal("Expected the activities to be added in one request, got:", requests[0])
+	}
+	if !strings.HasPrefix(requests[1], "POST /api/v1.0/feed/timeline/bob/following/") {
+		t.Fatal("Unexpected follow request:", requests[1])
+	}
+	if !strings.HasPrefix(requests[2], "DELETE /api/v1.0/feed/timeline/bob/following/user:alice/") {
+		t.Fatal("Unexpected unfollow request:", requests[2])
+	}
+}
+
+func TestDebug(t *testing.T) {
+	c, _, stderr, server := testCLI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [{"feed_id": "timeline:bob", "target_id": "user:alice"}]}`))
+	})
+	defer server.Close()
+
+	if code := c.run([]string{"-debug", "following", "timeline:bob"}); code != 0 {
+		t.Fatal("Unexpected exit code:", code)
+	}
+	if !strings.Contains(stderr.String(), "GET /api/v1.0/feed/timeline/bob/following/") || !strings.Contains(stderr.String(), `"target_id": "user:alice"`) {
+		t.Fatal("Expected the request and response to be printed, got:", stderr.String())
+	}
+}
+
+func TestToken(t *testing.T) {
+	c, stdout, _, server := testCLI(t, nil)
+	defer server.Close()
+
+	if code := c.run([]string{"token", "-resource", "feed", "-action", "read", "user:bob"}); code != 0 {
+		t.Fatal("Unexpected exit code:", code)
+	}
+
+	client, _ := c.newClient()
+	expected, _ := client.Signer.GenerateFeedScopeToken(getstream.ScopeContextFeed, getstream.ScopeActionRead, "userbob")
+	if strings.TrimSpace(stdout.String()) != expected {
+		t.Fatal("Unexpected token:", stdout.String())
+	}
+}
+
+func TestUsage(t *testing.T) {
+	c, _, stderr, server := testCLI(t, nil)
+	defer server.Close()
+
+	for _, args := range [][]string{
+		{},
+		{"unknown"},
+		{"read", "userbob"},
+		{"read", "-type", "ranked", "user:bob"},
+		{"token", "-resource", "nothing", "user:bob"},
+	} {
+		if code := c.run(args); code != 2 {
+			t.Fatal("Expected exit code 2 for", args, "got:", code)
+		}
+	}
+	if !strings.Contains(stderr.String(), "usage: getstream") {
+		t.Fatal("Expected usage to be printed, got:", stderr.String())
+	}
+}
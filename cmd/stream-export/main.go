@@ -0,0 +1,87 @@
+// Command stream-export writes the activities and follows of one or more flat feeds as
+// NDJSON, or as a tar archive with a file per feed, which stream-import can load again
+//
+// Credentials are read from STREAM_API_KEY, STREAM_API_SECRET, STREAM_APP_ID and,
+// optionally, STREAM_REGION and the other variables of getstream.LoadConfigFromEnv
+//
+//	stream-export -o bob.ndjson user:bob timeline:bob
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/exporter"
+)
+
+func main() {
+	output := flag.String("o", "", "file to write to, defaults to stdout")
+	archive := flag.Bool("tar", false, "write a tar archive with a file per feed")
+	limit := flag.Int("limit", 0, "maximum number of activities per feed, 0 exports all of them")
+	skipFollows := flag.Bool("skip-follows", false, "leave out followers and followed feeds")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: stream-export [flags] feed_slug:user_id ...")
+		os.Exit(2)
+	}
+
+	clientConfig, err := getstream.LoadConfigFromEnv()
+	var client *getstream.Client
+	if err == nil {
+		client, err = getstream.New(clientConfig)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	var feeds []*getstream.FlatFeed
+	for _, feedID := range flag.Args() {
+		parts := strings.SplitN(feedID, ":", 2)
+		if len(parts) != 2 {
+			fmt.Fprintln(os.Stderr, "invalid feed", feedID)
+			os.Exit(2)
+		}
+		feed, err := client.FlatFeed(parts[0], parts[1])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, feedID+":", err)
+			os.Exit(2)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	exp := exporter.New(&exporter.Config{
+		Limit:       *limit,
+		SkipFollows: *skipFollows,
+	})
+
+	var progress exporter.Progress
+	if *archive {
+		progress, err = exp.ExportTar(w, feeds...)
+	} else {
+		progress, err = exp.Export(w, feeds...)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d feeds, %d activities and %d follows\n",
+		progress.Feeds, progress.Activities, progress.Follows)
+}
@@ -0,0 +1,69 @@
+// Command stream-import loads an NDJSON file of activities and follows into a GetStream.io
+// application, see the importer package for the input format
+//
+// Credentials are read from STREAM_API_KEY, STREAM_API_SECRET, STREAM_APP_ID and,
+// optionally, STREAM_REGION and the other variables of getstream.LoadConfigFromEnv
+//
+//	stream-import -checkpoint import.checkpoint -rejects rejects.ndjson export.ndjson
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/importer"
+)
+
+func main() {
+	checkpoint := flag.String("checkpoint", "", "file to save the import position in, used to resume")
+	rejects := flag.String("rejects", "", "file to append rejected records to")
+	batchSize := flag.Int("batch-size", 100, "activities added per request")
+	copyLimit := flag.Int("copy-limit", 0, "activities copied into the source feed of every follow")
+	quiet := flag.Bool("quiet", false, "don't report progress")
+	flag.Parse()
+
+	if flag.NArg() > 1 {
+		fmt.Fprintln(os.Stderr, "usage: stream-import [flags] [file]")
+		os.Exit(2)
+	}
+
+	clientConfig, err := getstream.LoadConfigFromEnv()
+	var client *getstream.Client
+	if err == nil {
+		client, err = getstream.New(clientConfig)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	cfg := &importer.Config{
+		BatchSize:      *batchSize,
+		CopyLimit:      *copyLimit,
+		CheckpointPath: *checkpoint,
+		RejectsPath:    *rejects,
+	}
+	if !*quiet {
+		cfg.OnProgress = func(progress importer.Progress) {
+			fmt.Fprintf(os.Stderr, "line %d: %d activities, %d follows, %d rejected\n",
+				progress.Line, progress.Activities, progress.Follows, progress.Rejected)
+		}
+	}
+	imp := importer.New(client, cfg)
+
+	var progress importer.Progress
+	if flag.NArg() == 1 {
+		progress, err = imp.ImportFile(flag.Arg(0))
+	} else {
+		progress, err = imp.Import(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("imported %d activities and %d follows, %d rejected\n",
+		progress.Activities, progress.Follows, progress.Rejected)
+}
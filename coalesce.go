@@ -0,0 +1,101 @@
+package getstream
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"sync/atomic"
+)
+
+var errCoalescedCallFailed = errors.New("coalesced request failed without a response")
+
+// Coalescer shares a single request between concurrent identical GET requests of a Client,
+// add its Interceptor to Client.Interceptors to enable it
+type Coalescer struct {
+	// the counters come first to be 64-bit aligned for atomic
+	requests     int64
+	deduplicated int64
+
+	lock  sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done chan struct{}
+	resp *Response
+	err  error
+}
+
+// CoalescerStats counts the GET requests seen by a Coalescer
+type CoalescerStats struct {
+	Requests int64
+	// Deduplicated is the number of requests which shared the response of another request
+	Deduplicated int64
+}
+
+// NewCoalescer returns a Coalescer
+func NewCoalescer() *Coalescer {
+	return &Coalescer{
+		calls: map[string]*coalescedCall{},
+	}
+}
+
+// Interceptor returns the Interceptor coalescing requests, requests are identical when they have the
+// same method, URL, params, headers and authentication
+// Place it after CacheInterceptor, so that only cache misses are coalesced
+func (c *Coalescer) Interceptor() Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			if req.Method != "GET" {
+				return next(req)
+			}
+			atomic.AddInt64(&c.requests, 1)
+
+			key := coalesceKey(req)
+			c.lock.Lock()
+			if call, ok := c.calls[key]; ok {
+				c.lock.Unlock()
+				atomic.AddInt64(&c.deduplicated, 1)
+
+				<-call.done
+				if call.resp == nil {
+					return nil, call.err
+				}
+				resp := *call.resp
+				resp.Coalesced = true
+				return &resp, call.err
+			}
+			call := &coalescedCall{done: make(chan struct{}), err: errCoalescedCallFailed}
+			c.calls[key] = call
+			c.lock.Unlock()
+
+			// released even when next panics, the waiting requests then fail with errCoalescedCallFailed
+			defer func() {
+				c.lock.Lock()
+				delete(c.calls, key)
+				c.lock.Unlock()
+				close(call.done)
+			}()
+
+			resp, err := next(req)
+			call.resp, call.err = resp, err
+			return resp, err
+		}
+	}
+}
+
+// Stats returns the number of requests seen and deduplicated so far
+func (c *Coalescer) Stats() CoalescerStats {
+	return CoalescerStats{
+		Requests:     atomic.LoadInt64(&c.requests),
+		Deduplicated: atomic.LoadInt64(&c.deduplicated),
+	}
+}
+
+func coalesceKey(req *Request) string {
+	base := ""
+	if req.BaseURL != nil {
+		base = req.BaseURL.String()
+	}
+	return string(req.Auth) + " " + req.Token + " " + base + cacheRequest(req) + " " + url.Values(req.Header).Encode()
+}
@@ -0,0 +1,119 @@
+package getstream_test
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestCoalescer(t *testing.T) {
+	var calls int64
+	release := make(chan struct{})
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		<-release
+		w.Write([]byte(`{"results": [{"id": "1", "actor": "bob", "verb": "post", "object": "post:1"}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	coalescer := getstream.NewCoalescer()
+	client.Interceptors = []getstream.Interceptor{coalescer.Interceptor()}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 6)
+	read := func(input *getstream.GetFlatFeedInput) {
+		defer wg.Done()
+		output, err := feed.Activities(input)
+		if err == nil && len(output.Activities) != 1 {
+			t.Error("Unexpected activities:", output.Activities)
+		}
+		errs <- err
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go read(nil)
+	}
+	wg.Add(1)
+	go read(&getstream.GetFlatFeedInput{Limit: 5})
+
+	for coalescer.Stats().Requests < 6 || atomic.LoadInt64(&calls) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats := coalescer.Stats()
+	if atomic.LoadInt64(&calls) != 2 || stats.Requests != 6 || stats.Deduplicated != 4 {
+		t.Fatal("Expected the identical reads to share a request, got:", calls, stats)
+	}
+}
+
+func TestCoalescerPanic(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	coalescer := getstream.NewCoalescer()
+	client.Interceptors = []getstream.Interceptor{
+		coalescer.Interceptor(),
+		func(next getstream.RoundTrip) getstream.RoundTrip {
+			return func(req *getstream.Request) (*getstream.Response, error) {
+				for coalescer.Stats().Deduplicated < 1 {
+					time.Sleep(time.Millisecond)
+				}
+				panic("interceptor failed")
+			}
+		},
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		defer func() {
+			recover()
+		}()
+		feed.Activities(nil)
+	}()
+	for coalescer.Stats().Requests < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	done := make(chan error)
+	go func() {
+		_, err := feed.Activities(nil)
+		done <- err
+	}()
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("Expected the waiting request to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the waiting request to be released when the shared request panics")
+	}
+}
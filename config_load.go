@@ -0,0 +1,262 @@
+package getstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Versions lists the supported API versions
+var Versions = []string{"v1.0"}
+
+// configEnv maps the config keys to the environment variables read by LoadConfigFromEnv
+var configEnv = map[string]string{
+	"api_key":    "STREAM_API_KEY",
+	"api_secret": "STREAM_API_SECRET",
+	"app_id":     "STREAM_APP_ID",
+	"location":   "STREAM_REGION",
+	"version":    "STREAM_API_VERSION",
+	"token":      "STREAM_TOKEN",
+	"timeout":    "STREAM_TIMEOUT",
+}
+
+// LoadConfigFromEnv reads a Config from the environment variables STREAM_API_KEY, STREAM_API_SECRET,
+// STREAM_APP_ID, STREAM_REGION, STREAM_API_VERSION, STREAM_TOKEN and STREAM_TIMEOUT, and validates it
+// The timeout is a duration such as "500ms" or "5s", or a number of seconds
+func LoadConfigFromEnv() (*Config, error) {
+	values := map[string]string{}
+	for key, name := range configEnv {
+		if value := os.Getenv(name); value != "" {
+			values[key] = value
+		}
+	}
+	return configFromValues(values)
+}
+
+// LoadConfigFromFile reads a Config from a JSON (.json), YAML (.yaml, .yml) or TOML (.toml) file, and validates it
+// The file holds the keys api_key, api_secret, app_id, location, version, token and timeout at its top level
+// Only a flat subset of YAML and TOML is read: one key: value (YAML) or key = value (TOML) line per key,
+// with optionally quoted values and # comments; nested keys, tables, lists and multi-line strings are rejected
+func LoadConfigFromFile(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		values, err = parseJSONConfig(data)
+	case ".yaml", ".yml":
+		values, err = parseFlatConfig(data, ":")
+	case ".toml":
+		values, err = parseFlatConfig(data, "=")
+	default:
+		return nil, errors.New("Unsupported config file format " + filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return configFromValues(values)
+}
+
+// Validate checks that the Config has credentials and a known location, version and timeout
+func (c *Config) Validate() error {
+	err := c.validateCredentials()
+	if err != nil {
+		return err
+	}
+	if c.Location != "" && c.Location != "qa" && c.Location != "localhost" && !contains(Locations, c.Location) {
+		return errors.New("Unknown location " + c.Location + ", expected one of " + strings.Join(Locations, ", "))
+	}
+	if c.Version != "" && !contains(Versions, c.Version) {
+		return errors.New("Unsupported API version " + c.Version + ", expected one of " + strings.Join(Versions, ", "))
+	}
+	if c.TimeoutInt < 0 || c.TimeoutDuration < 0 {
+		return errors.New("Timeout can't be negative")
+	}
+	return nil
+}
+
+// validateCredentials checks the fields New can't do without
+func (c *Config) validateCredentials() error {
+	if c.APIKey == "" {
+		return errors.New("Required API Key was not set")
+	}
+	if c.APISecret == "" && c.Token == "" {
+		return errors.New("API Secret or Token was not set, one or the other is required")
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func configFromValues(values map[string]string) (*Config, error) {
+	cfg := &Config{}
+	for key, value := range values {
+		switch key {
+		case "api_key":
+			cfg.APIKey = value
+		case "api_secret":
+			cfg.APISecret = value
+		case "app_id":
+			cfg.AppID = value
+		case "location":
+			cfg.Location = value
+		case "version":
+			cfg.Version = value
+		case "token":
+			cfg.Token = value
+		case "timeout":
+			timeout, err := parseTimeout(value)
+			if err != nil {
+				return nil, err
+			}
+			cfg.TimeoutDuration = timeout
+			cfg.TimeoutInt = int64(timeout / time.Second)
+		default:
+			return nil, errors.New("Unknown config key " + key)
+		}
+	}
+
+	err := cfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseTimeout reads a duration such as "500ms", or a number of seconds
+func parseTimeout(value string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(value)
+	if err == nil {
+		return timeout, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.New("Invalid timeout " + value + ", expected a duration such as 5s or a number of seconds")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func parseJSONConfig(data []byte) (map[string]string, error) {
+	var raw map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for key, value := range raw {
+		switch value := value.(type) {
+		case string:
+			values[key] = value
+		case json.Number:
+			values[key] = value.String()
+		default:
+			return nil, errors.New("Config key " + key + " must be a string or a number")
+		}
+	}
+	return values, nil
+}
+
+// parseFlatConfig reads "key: value" (YAML) or "key = value" (TOML) lines, with # comments
+// and optionally quoted values
+func parseFlatConfig(data []byte, separator string) (map[string]string, error) {
+	values := map[string]string{}
+	for i, line := range strings.Split(string(data), "\n") {
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		line = strings.TrimSpace(line)
+		if line == "" || line == "---" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lineNumber := strconv.Itoa(i + 1)
+		unsupported := func(what string) error {
+			return errors.New("Unsupported config line " + lineNumber + ": " + what + " aren't supported, " +
+				"config files are read as flat key" + separator + " value lines")
+		}
+
+		switch {
+		case strings.HasPrefix(line, "["):
+			return nil, unsupported("tables")
+		case strings.HasPrefix(line, "- "):
+			return nil, unsupported("lists")
+		case indented:
+			return nil, unsupported("nested keys")
+		}
+
+		index := strings.Index(line, separator)
+		if index < 0 {
+			return nil, errors.New("Invalid config line " + lineNumber + ", expected key" + separator + " value")
+		}
+		key := strings.Trim(strings.TrimSpace(line[:index]), `"'`)
+		raw := strings.TrimSpace(line[index+1:])
+		if raw != "" && strings.ContainsAny(raw[:1], "[{|>") {
+			return nil, unsupported("lists, maps and multi-line strings")
+		}
+		value, err := parseFlatValue(raw)
+		if err != nil {
+			return nil, errors.New("Invalid config value on line " + lineNumber + ": " + err.Error())
+		}
+
+		if _, ok := values[key]; ok {
+			return nil, errors.New("Duplicate config key " + key + " on line " + lineNumber)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func parseFlatValue(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	switch value[0] {
+	case '"':
+		end := 1
+		for ; end < len(value); end++ {
+			if value[end] == '\\' {
+				end++
+			} else if value[end] == '"' {
+				break
+			}
+		}
+		if end >= len(value) {
+			return "", errors.New("unterminated string")
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", errors.New("unexpected " + rest)
+		}
+		return strconv.Unquote(value[:end+1])
+	case '\'':
+		end := strings.Index(value[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated string")
+		}
+		if rest := strings.TrimSpace(value[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", errors.New("unexpected " + rest)
+		}
+		return value[1 : end+1], nil
+	}
+
+	if index := strings.Index(value, " #"); index >= 0 {
+		value = strings.TrimSpace(value[:index])
+	}
+	return value, nil
+}
@@ -0,0 +1,174 @@
+package getstream_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func setEnv(t *testing.T, values map[string]string) func() {
+	previous := map[string]string{}
+	for key, value := range values {
+		previous[key] = os.Getenv(key)
+		if err := os.Setenv(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return func() {
+		for key, value := range previous {
+			os.Setenv(key, value)
+		}
+	}
+}
+
+func TestLoadConfigFromEnv(t *testing.T) {
+	restore := setEnv(t, map[string]string{
+		"STREAM_API_KEY":     "my_key",
+		"STREAM_API_SECRET":  "my_secret",
+		"STREAM_APP_ID":      "111111",
+		"STREAM_REGION":      "eu-west",
+		"STREAM_API_VERSION": "",
+		"STREAM_TOKEN":       "",
+		"STREAM_TIMEOUT":     "1.5",
+	})
+	defer restore()
+
+	cfg, err := getstream.LoadConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "my_key" || cfg.APISecret != "my_secret" || cfg.AppID != "111111" || cfg.Location != "eu-west" {
+		t.Fatal("Unexpected config:", cfg)
+	}
+	if cfg.TimeoutDuration != 1500*time.Millisecond || cfg.TimeoutInt != 1 {
+		t.Fatal("Unexpected timeout:", cfg.TimeoutDuration, cfg.TimeoutInt)
+	}
+
+	os.Setenv("STREAM_REGION", "mars")
+	if _, err = getstream.LoadConfigFromEnv(); err == nil {
+		t.Fatal("Expected an unknown location to fail")
+	}
+	os.Setenv("STREAM_REGION", "")
+	os.Setenv("STREAM_API_VERSION", "v2.0")
+	if _, err = getstream.LoadConfigFromEnv(); err == nil {
+		t.Fatal("Expected an unsupported version to fail")
+	}
+	os.Setenv("STREAM_API_VERSION", "")
+	os.Setenv("STREAM_TIMEOUT", "soon")
+	if _, err = getstream.LoadConfigFromEnv(); err == nil {
+		t.Fatal("Expected an invalid timeout to fail")
+	}
+}
+
+func TestLoadConfigFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"stream.json": `{"api_key": "my_key", "api_secret": "my_secret", "app_id": 111111, "location": "us-east", "timeout": "500ms"}`,
+		"stream.yaml": `---
+# production app
+api_key: my_key
+api_secret: "my_secret"
+app_id: 111111
+location: us-east # nearest
+timeout: 500ms
+`,
+		"stream.toml": `# production app
+api_key = "my_key"
+api_secret = 'my_secret'
+app_id = "111111"
+location = "us-east"
+timeout = "500ms"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+
+		cfg, err := getstream.LoadConfigFromFile(path)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if cfg.APIKey != "my_key" || cfg.APISecret != "my_secret" || cfg.AppID != "111111" || cfg.Location != "us-east" || cfg.TimeoutDuration != 500*time.Millisecond {
+			t.Fatal("Unexpected config from", name, cfg)
+		}
+	}
+
+	invalid := map[string]string{
+		"unknown.json":   `{"api_key": "my_key", "api_secret": "my_secret", "secret": "typo"}`,
+		"missing.yaml":   `api_key: my_key`,
+		"table.toml":     "[stream]\napi_key = \"my_key\"",
+		"duplicate.toml": "api_key = \"my_key\"\napi_key = \"other\"\napi_secret = \"my_secret\"",
+		"stream.ini":     "api_key=my_key",
+		"nested.yaml":    "stream:\n  api_key: my_key\n  api_secret: my_secret",
+		"list.yaml":      "api_key: [my_key]\napi_secret: my_secret",
+	}
+	for name, content := range invalid {
+		path := filepath.Join(dir, name)
+		if err = ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err = getstream.LoadConfigFromFile(path)
+		if err == nil {
+			t.Fatal("Expected an error for", name)
+		}
+		if (name == "table.toml" || name == "nested.yaml" || name == "list.yaml") && !strings.Contains(err.Error(), "aren't supported") {
+			t.Fatal("Expected the unsupported syntax to be named, got:", err)
+		}
+	}
+}
+
+func TestNewCopiesConfig(t *testing.T) {
+	cfg := &getstream.Config{
+		APIKey:          "my_key",
+		APISecret:       "my_secret",
+		Token:           "my_token",
+		TimeoutDuration: 500 * time.Millisecond,
+	}
+	client, err := getstream.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.APISecret != "my_secret" || cfg.Version != "" || cfg.BaseURL != nil {
+		t.Fatal("Expected New to leave the config as it is, got:", cfg)
+	}
+	if client.Config == cfg || client.Config.APISecret != "" || client.Config.Version != "v1.0" {
+		t.Fatal("Expected the client to have its own config, got:", client.Config)
+	}
+	if client.HTTP.Timeout != 500*time.Millisecond {
+		t.Fatal("Expected a timeout of less than a second to be kept, got:", client.HTTP.Timeout)
+	}
+}
+
+func TestNewChecksCredentials(t *testing.T) {
+	invalid := []*getstream.Config{
+		{APISecret: "my_secret"},
+		{APIKey: "my_key"},
+	}
+	for _, cfg := range invalid {
+		if _, err := getstream.New(cfg); err == nil {
+			t.Fatal("Expected New to reject the config:", cfg)
+		}
+	}
+
+	// locations and versions missing from the known lists are left to the API
+	client, err := getstream.New(&getstream.Config{APIKey: "my_key", Token: "my_token", Location: "mars", Version: "v2.0", TimeoutInt: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.BaseURL.String() != "https://mars-api.getstream.io/api/v2.0/" {
+		t.Fatal("Unexpected base url:", client.BaseURL.String())
+	}
+}
@@ -0,0 +1,225 @@
+// Package exporter dumps FlatFeeds and their follow relationships from GetStream.io
+// as newline delimited JSON, in the Record format read by the importer package, so an
+// export can be loaded into another application:
+//
+//	{"type": "activity", "feed": "user:bob", "activity": {"actor": "bob", "verb": "post", "object": "post:1", "foreign_id": "post:1", "time": "2017-01-01T10:00:00"}}
+//	{"type": "follow", "source": "timeline:bob", "target": "user:bob"}
+//
+// All custom fields of the activities are kept. Activities are written newest first,
+// their time is exported so the order is restored on import.
+package exporter
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/importer"
+)
+
+// Progress reports what an export wrote
+type Progress struct {
+	Feeds      int
+	Activities int
+	Follows    int
+}
+
+// Config configures an Exporter
+type Config struct {
+	// PageSize is the number of activities or follows read per request, defaults to 100
+	PageSize int
+	// Limit is the maximum number of activities exported per feed, 0 exports all of them
+	Limit int
+	// SkipFollows leaves the followers and followed feeds out of the export
+	SkipFollows bool
+}
+
+// Exporter writes FlatFeeds as importer Records
+type Exporter struct {
+	config Config
+}
+
+// New returns an Exporter
+func New(cfg *Config) *Exporter {
+	config := Config{}
+	if cfg != nil {
+		config = *cfg
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = 100
+	}
+	if config.Limit < 0 {
+		config.Limit = 0
+	}
+
+	return &Exporter{
+		config: config,
+	}
+}
+
+// Export writes the activities, followers and followed feeds of every feed to w as NDJSON
+// A follow between two of the exported feeds is only written once
+func (e *Exporter) Export(w io.Writer, feeds ...*getstream.FlatFeed) (Progress, error) {
+	progress := Progress{}
+	written := make(map[getstream.PostFlatFeedFollowingManyInput]bool)
+
+	for _, feed := range feeds {
+		err := e.exportFeed(w, feed, written, &progress)
+		if err != nil {
+			return progress, err
+		}
+	}
+
+	return progress, nil
+}
+
+// ExportTar writes a tar archive to w with an NDJSON file per feed, named after the feed
+// such as "user/bob.ndjson". Each file can be imported on its own
+func (e *Exporter) ExportTar(w io.Writer, feeds ...*getstream.FlatFeed) (Progress, error) {
+	progress := Progress{}
+	written := make(map[getstream.PostFlatFeedFollowingManyInput]bool)
+	archive := tar.NewWriter(w)
+
+	for _, feed := range feeds {
+		// the size of a tar entry is needed up front
+		buffer := &bytes.Buffer{}
+		err := e.exportFeed(buffer, feed, written, &progress)
+		if err != nil {
+			return progress, err
+		}
+
+		err = archive.WriteHeader(&tar.Header{
+			Name:    feed.FeedSlug + "/" + feed.UserID + ".ndjson",
+			Mode:    0644,
+			Size:    int64(buffer.Len()),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return progress, err
+		}
+		_, err = buffer.WriteTo(archive)
+		if err != nil {
+			return progress, err
+		}
+	}
+
+	return progress, archive.Close()
+}
+
+func (e *Exporter) exportFeed(w io.Writer, feed *getstream.FlatFeed, written map[getstream.PostFlatFeedFollowingManyInput]bool, progress *Progress) error {
+	encoder := json.NewEncoder(w)
+	feedID := feed.FeedID().Value()
+
+	err := e.eachActivity(feed, func(activity *getstream.Activity) error {
+		// the id and origin belong to the exported application
+		exported := *activity
+		exported.ID = ""
+		exported.Origin = ""
+		exported.Score = 0
+
+		progress.Activities++
+		return encoder.Encode(&importer.Record{
+			Type:     importer.RecordActivity,
+			Feed:     feedID,
+			Activity: &exported,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if !e.config.SkipFollows {
+		writeFollow := func(follow getstream.PostFlatFeedFollowingManyInput) error {
+			if written[follow] {
+				return nil
+			}
+			written[follow] = true
+
+			progress.Follows++
+			return encoder.Encode(&importer.Record{
+				Type:   importer.RecordFollow,
+				Source: follow.Source,
+				Target: follow.Target,
+			})
+		}
+
+		err = eachFeed(e.config.PageSize, feed.FollowingWithLimitAndSkip, func(target *getstream.GeneralFeed) error {
+			return writeFollow(getstream.PostFlatFeedFollowingManyInput{
+				Source: feedID,
+				Target: target.FeedID().Value(),
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		err = eachFeed(e.config.PageSize, feed.FollowersWithLimitAndSkip, func(source *getstream.GeneralFeed) error {
+			return writeFollow(getstream.PostFlatFeedFollowingManyInput{
+				Source: source.FeedID().Value(),
+				Target: feedID,
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	progress.Feeds++
+	return nil
+}
+
+// eachActivity pages through the activities of a feed, newest first, up to the configured limit
+func (e *Exporter) eachActivity(feed *getstream.FlatFeed, fn func(activity *getstream.Activity) error) error {
+	input := &getstream.GetFlatFeedInput{
+		Limit: e.config.PageSize,
+	}
+
+	read := 0
+	for {
+		if e.config.Limit > 0 && e.config.Limit-read < input.Limit {
+			input.Limit = e.config.Limit - read
+		}
+
+		output, err := feed.Activities(input)
+		if err != nil {
+			return err
+		}
+
+		for _, activity := range output.Activities {
+			if err := fn(activity); err != nil {
+				return err
+			}
+		}
+		read += len(output.Activities)
+
+		if len(output.Activities) < input.Limit || (e.config.Limit > 0 && read >= e.config.Limit) {
+			return nil
+		}
+		input.IDLT = output.Activities[len(output.Activities)-1].ID
+	}
+}
+
+// eachFeed pages through the followers or followed feeds of a feed
+func eachFeed(pageSize int, page func(limit int, skip int) ([]*getstream.GeneralFeed, error), fn func(feed *getstream.GeneralFeed) error) error {
+	skip := 0
+	for {
+		feeds, err := page(pageSize, skip)
+		if err != nil {
+			return err
+		}
+
+		for _, feed := range feeds {
+			if err := fn(feed); err != nil {
+				return err
+			}
+		}
+
+		if len(feeds) < pageSize {
+			return nil
+		}
+		skip += len(feeds)
+	}
+}
@@ -0,0 +1,219 @@
+package exporter_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/exporter"
+	"github.com/GetStream/stream-go/importer"
+)
+
+// api serves 5 activities for user:bob, which follows user:alice and is followed by two timelines
+type api struct {
+	posted []string
+}
+
+func (a *api) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1.0/")
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	offset, _ := strconv.Atoi(query.Get("offset"))
+
+	if r.Method == "POST" {
+		body, _ := ioutil.ReadAll(r.Body)
+		a.posted = append(a.posted, path+" "+string(body))
+		w.Write([]byte(`{"activities": []}`))
+		return
+	}
+
+	var results []map[string]interface{}
+	switch path {
+	case "feed/user/bob/":
+		// ids 5 to 1, newest first
+		for id := 5; id >= 1 && len(results) < limit; id-- {
+			if idLT := query.Get("id_lt"); idLT != "" && strconv.Itoa(id) >= idLT {
+				continue
+			}
+			results = append(results, map[string]interface{}{
+				"id":         strconv.Itoa(id),
+				"actor":      "bob",
+				"verb":       "post",
+				"object":     fmt.Sprintf("post:%d", id),
+				"foreign_id": fmt.Sprintf("post:%d", id),
+				"time":       fmt.Sprintf("2017-01-0%dT10:00:00", id),
+				"origin":     nil,
+				"likes":      id * 10,
+				"tags":       []string{"a", "b"},
+			})
+		}
+	case "feed/user/bob/following/":
+		results = append(results, map[string]interface{}{"feed_id": "user:bob", "target_id": "user:alice"})
+	case "feed/user/bob/followers/":
+		for _, follower := range []string{"timeline:alice", "timeline:eve"}[offset:] {
+			if len(results) < limit {
+				results = append(results, map[string]interface{}{"feed_id": follower, "target_id": "user:bob"})
+			}
+		}
+	case "feed/user/alice/", "feed/user/alice/following/":
+	case "feed/user/alice/followers/":
+		results = append(results, map[string]interface{}{"feed_id": "user:bob", "target_id": "user:alice"})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 404, "exception": "NotFound"}`))
+		return
+	}
+
+	payload, _ := json.Marshal(map[string]interface{}{"results": results})
+	w.Write(payload)
+}
+
+func setup(t *testing.T, handler http.Handler) (*getstream.Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, err = url.Parse(server.URL + "/api/v1.0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, server
+}
+
+func feeds(t *testing.T, client *getstream.Client, userIDs ...string) []*getstream.FlatFeed {
+	var result []*getstream.FlatFeed
+	for _, userID := range userIDs {
+		feed, err := client.FlatFeed("user", userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, feed)
+	}
+	return result
+}
+
+func TestExport(t *testing.T) {
+	client, server := setup(t, &api{})
+	defer server.Close()
+
+	buffer := &bytes.Buffer{}
+	progress, err := exporter.New(&exporter.Config{PageSize: 2}).Export(buffer, feeds(t, client, "bob", "alice")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Feeds != 2 || progress.Activities != 5 || progress.Follows != 3 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	if len(lines) != 8 {
+		t.Fatal("Expected 8 records, got:", buffer.String())
+	}
+
+	record := &importer.Record{}
+	err = json.Unmarshal([]byte(lines[0]), record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Type != importer.RecordActivity || record.Feed != "user:bob" || record.Activity.ForeignID != "post:5" || record.Activity.ID != "" {
+		t.Fatal("Unexpected activity record:", lines[0])
+	}
+	if string(*record.Activity.Extra["likes"]) != "50" || record.Activity.TimeStamp.Day() != 5 {
+		t.Fatal("Expected custom fields and time to be exported, got:", lines[0])
+	}
+
+	expected := []string{
+		`{"type":"follow","source":"user:bob","target":"user:alice"}`,
+		`{"type":"follow","source":"timeline:alice","target":"user:bob"}`,
+		`{"type":"follow","source":"timeline:eve","target":"user:bob"}`,
+	}
+	if strings.Join(lines[5:], "\n") != strings.Join(expected, "\n") {
+		t.Fatal("Unexpected follow records:", lines[5:])
+	}
+}
+
+func TestExportLimit(t *testing.T) {
+	client, server := setup(t, &api{})
+	defer server.Close()
+
+	buffer := &bytes.Buffer{}
+	progress, err := exporter.New(&exporter.Config{PageSize: 2, Limit: 3, SkipFollows: true}).Export(buffer, feeds(t, client, "bob")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Activities != 3 || progress.Follows != 0 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+}
+
+func TestExportTar(t *testing.T) {
+	client, server := setup(t, &api{})
+	defer server.Close()
+
+	buffer := &bytes.Buffer{}
+	_, err := exporter.New(nil).ExportTar(buffer, feeds(t, client, "bob", "alice")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	archive := tar.NewReader(buffer)
+	for {
+		header, err := archive.Next()
+		if err != nil {
+			break
+		}
+		content, _ := ioutil.ReadAll(archive)
+		names = append(names, fmt.Sprintf("%s:%d", header.Name, strings.Count(string(content), "\n")))
+	}
+	if strings.Join(names, ",") != "user/bob.ndjson:8,user/alice.ndjson:0" {
+		t.Fatal("Unexpected archive:", names)
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	source, server := setup(t, &api{})
+	defer server.Close()
+
+	buffer := &bytes.Buffer{}
+	_, err := exporter.New(nil).Export(buffer, feeds(t, source, "bob")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	destination := &api{}
+	target, targetServer := setup(t, destination)
+	defer targetServer.Close()
+
+	progress, err := importer.New(target, nil).Import(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Activities != 5 || progress.Follows != 3 || progress.Rejected != 0 {
+		t.Fatal("Unexpected import:", progress)
+	}
+
+	var batch struct {
+		Activities []map[string]interface{} `json:"activities"`
+	}
+	json.Unmarshal([]byte(strings.TrimPrefix(destination.posted[0], "feed/user/bob/ ")), &batch)
+	if len(batch.Activities) != 5 || batch.Activities[0]["likes"] != 50.0 || batch.Activities[0]["time"] != "2017-01-05T10:00:00" {
+		t.Fatal("Unexpected imported activities:", destination.posted[0])
+	}
+}
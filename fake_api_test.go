@@ -0,0 +1,183 @@
+package getstream_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// fakeAPI is an in-memory stand-in for the feed API, covering activities and follows
+type fakeAPI struct {
+	sync.Mutex
+
+	nextID     int
+	activities map[string][]map[string]interface{} // feed id => activities, oldest first
+	follows    map[string][]string                 // feed id => followed feed ids
+	requests   []string
+}
+
+func newFakeAPI() *fakeAPI {
+	return &fakeAPI{
+		activities: make(map[string][]map[string]interface{}),
+		follows:    make(map[string][]string),
+	}
+}
+
+// add stores an activity directly, bypassing the API
+func (f *fakeAPI) add(feedID string, activity map[string]interface{}) map[string]interface{} {
+	f.Lock()
+	defer f.Unlock()
+
+	return f.addLocked(feedID, activity)
+}
+
+func (f *fakeAPI) addLocked(feedID string, activity map[string]interface{}) map[string]interface{} {
+	f.nextID++
+	stored := map[string]interface{}{}
+	for key, value := range activity {
+		stored[key] = value
+	}
+	stored["id"] = fmt.Sprintf("%08d", f.nextID)
+	stored["origin"] = nil
+	f.activities[feedID] = append(f.activities[feedID], stored)
+	return stored
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	f.requests = append(f.requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, "/api/v1.0/"))
+
+	body, _ := ioutil.ReadAll(r.Body)
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1.0/"), "/"), "/")
+	query := r.URL.Query()
+
+	write := func(value interface{}) {
+		payload, _ := json.Marshal(value)
+		w.Write(payload)
+	}
+
+	if parts[0] == "follow_many" && r.Method == "POST" {
+		var follows []map[string]string
+		json.Unmarshal(body, &follows)
+		for _, follow := range follows {
+			f.follows[follow["source"]] = append(f.follows[follow["source"]], follow["target"])
+		}
+		write(map[string]interface{}{})
+		return
+	}
+
+	if parts[0] != "feed" || len(parts) < 3 {
+		w.WriteHeader(http.StatusNotFound)
+		write(map[string]interface{}{"code": 404, "exception": "NotFound"})
+		return
+	}
+
+	feedID := parts[1] + ":" + parts[2]
+	rest := parts[3:]
+
+	switch {
+	case len(rest) == 0 && r.Method == "GET":
+		activities := f.activities[feedID]
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		if limit <= 0 {
+			limit = 25
+		}
+		results := []map[string]interface{}{}
+		for i := len(activities) - 1; i >= 0 && len(results) < limit; i-- {
+			if idLT := query.Get("id_lt"); idLT != "" && activities[i]["id"].(string) >= idLT {
+				continue
+			}
+			results = append(results, activities[i])
+		}
+		write(map[string]interface{}{"results": results})
+
+	case len(rest) == 0 && r.Method == "POST":
+		var batch struct {
+			Activities []map[string]interface{} `json:"activities"`
+		}
+		json.Unmarshal(body, &batch)
+		if batch.Activities == nil {
+			var activity map[string]interface{}
+			json.Unmarshal(body, &activity)
+			write(f.addLocked(feedID, activity))
+			return
+		}
+		var added []map[string]interface{}
+		for _, activity := range batch.Activities {
+			added = append(added, f.addLocked(feedID, activity))
+		}
+		write(map[string]interface{}{"activities": added})
+
+	case len(rest) == 1 && rest[0] == "following" && r.Method == "POST":
+		var follow map[string]interface{}
+		json.Unmarshal(body, &follow)
+		f.follows[feedID] = append(f.follows[feedID], follow["target"].(string))
+		write(map[string]interface{}{})
+
+	case len(rest) == 1 && (rest[0] == "following" || rest[0] == "followers") && r.Method == "GET":
+		var results []map[string]string
+		if rest[0] == "following" {
+			for _, target := range f.follows[feedID] {
+				results = append(results, map[string]string{"feed_id": feedID, "target_id": target})
+			}
+		} else {
+			var sources []string
+			for source := range f.follows {
+				sources = append(sources, source)
+			}
+			sort.Strings(sources)
+			for _, source := range sources {
+				for _, target := range f.follows[source] {
+					if target == feedID {
+						results = append(results, map[string]string{"feed_id": source, "target_id": target})
+					}
+				}
+			}
+		}
+		offset, _ := strconv.Atoi(query.Get("offset"))
+		limit, _ := strconv.Atoi(query.Get("limit"))
+		if offset > len(results) {
+			offset = len(results)
+		}
+		results = results[offset:]
+		if limit > 0 && limit < len(results) {
+			results = results[:limit]
+		}
+		write(map[string]interface{}{"results": results})
+
+	case len(rest) == 2 && rest[0] == "following" && r.Method == "DELETE":
+		var kept []string
+		for _, target := range f.follows[feedID] {
+			if target != rest[1] {
+				kept = append(kept, target)
+			}
+		}
+		f.follows[feedID] = kept
+		write(map[string]interface{}{})
+
+	case len(rest) == 1 && r.Method == "DELETE":
+		field := "id"
+		if query.Get("foreign_id") == "1" {
+			field = "foreign_id"
+		}
+		var kept []map[string]interface{}
+		for _, activity := range f.activities[feedID] {
+			if activity[field] != rest[0] {
+				kept = append(kept, activity)
+			}
+		}
+		f.activities[feedID] = kept
+		write(map[string]interface{}{})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		write(map[string]interface{}{"code": 404, "exception": "NotFound"})
+	}
+}
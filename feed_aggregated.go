@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type postAggregatedFeedOutputActivities struct {
@@ -21,96 +23,95 @@ type GetAggregatedFeedInput struct {
 	IDLTE string `json:"id_lte,omitempty"`
 	IDLT  string `json:"id_lt,omitempty"`
 
-	Ranking string `json:"ranking,omitempty"`
+	Ranking             string                 `json:"ranking,omitempty"`
+	RankingVars         map[string]interface{} `json:"ranking_vars,omitempty"`
+	ExternalRankingVars map[string]interface{} `json:"external_ranking_vars,omitempty"`
+	SessionID           string                 `json:"session_id,omitempty"`
+
+	// reaction options, only honoured by EnrichedActivities
+	WithReactionCounts   bool `json:"withReactionCounts,omitempty"`
+	WithOwnReactions     bool `json:"withOwnReactions,omitempty"`
+	WithRecentReactions  bool `json:"withRecentReactions,omitempty"`
+	RecentReactionsLimit int  `json:"recentReactionsLimit,omitempty"`
 }
 
-// GetAggregatedFeedOutput is the response from a AggregatedFeed Activities Get Request
-type GetAggregatedFeedOutput struct {
-	Duration string
-	Next     string
-	Results  []*struct {
-		Activities    []*Activity
-		ActivityCount int
-		ActorCount    int
-		CreatedAt     string
-		Group         string
-		ID            string
-		UpdatedAt     string
-		Verb          string
-	}
+// params encodes the input as query params for a feed read
+func (i *GetAggregatedFeedInput) params() (map[string]string, error) {
+	return feedReadParams{
+		Limit:                i.Limit,
+		Offset:               i.Offset,
+		IDGTE:                i.IDGTE,
+		IDGT:                 i.IDGT,
+		IDLTE:                i.IDLTE,
+		IDLT:                 i.IDLT,
+		Ranking:              i.Ranking,
+		RankingVars:          i.RankingVars,
+		ExternalRankingVars:  i.ExternalRankingVars,
+		SessionID:            i.SessionID,
+		WithReactionCounts:   i.WithReactionCounts,
+		WithOwnReactions:     i.WithOwnReactions,
+		WithRecentReactions:  i.WithRecentReactions,
+		RecentReactionsLimit: i.RecentReactionsLimit,
+	}.params()
 }
 
-type getAggregatedFeedOutput struct {
-	Duration string                           `json:"duration"`
-	Next     string                           `json:"next"`
-	Results  []*getAggregatedFeedOutputResult `json:"results"`
+// AggregatedGroup is a group of Activities returned from an AggregatedFeed
+type AggregatedGroup struct {
+	Activities    []*Activity `json:"activities"`
+	ActivityCount int         `json:"activity_count"`
+	ActorCount    int         `json:"actor_count"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Group         string      `json:"group"`
+	ID            string      `json:"id"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	Verb          string      `json:"verb"`
 }
 
-func (a getAggregatedFeedOutput) output() *GetAggregatedFeedOutput {
+// MarshalJSON is the custom marshal function for AggregatedGroups
+// It writes the timestamps in the format of the API, which UnmarshalJSON reads back
+func (g AggregatedGroup) MarshalJSON() ([]byte, error) {
+	type group AggregatedGroup
+	return json.Marshal(struct {
+		group
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{
+		group:     group(g),
+		CreatedAt: formatTime(g.CreatedAt),
+		UpdatedAt: formatTime(g.UpdatedAt),
+	})
+}
 
-	output := GetAggregatedFeedOutput{
-		Duration: a.Duration,
-		Next:     a.Next,
+// UnmarshalJSON is the custom unmarshal function for AggregatedGroups
+// Timestamps the API sends in an unknown format are left zero
+func (g *AggregatedGroup) UnmarshalJSON(b []byte) error {
+	type group AggregatedGroup
+	payload := struct {
+		*group
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{group: (*group)(g)}
+
+	err := json.Unmarshal(b, &payload)
+	if err != nil {
+		return err
 	}
 
-	var results []*struct {
-		Activities    []*Activity
-		ActivityCount int
-		ActorCount    int
-		CreatedAt     string
-		Group         string
-		ID            string
-		UpdatedAt     string
-		Verb          string
+	if createdAt, err := parseTime(payload.CreatedAt); err == nil {
+		g.CreatedAt = createdAt
 	}
-
-	for _, result := range a.Results {
-
-		outputResult := struct {
-			Activities    []*Activity
-			ActivityCount int
-			ActorCount    int
-			CreatedAt     string
-			Group         string
-			ID            string
-			UpdatedAt     string
-			Verb          string
-		}{
-			ActivityCount: result.ActivityCount,
-			ActorCount:    result.ActorCount,
-			CreatedAt:     result.CreatedAt,
-			Group:         result.Group,
-			ID:            result.ID,
-			UpdatedAt:     result.UpdatedAt,
-			Verb:          result.Verb,
-		}
-
-		for _, activity := range result.Activities {
-			outputResult.Activities = append(outputResult.Activities, activity)
-		}
-
-		results = append(results, &outputResult)
+	if updatedAt, err := parseTime(payload.UpdatedAt); err == nil {
+		g.UpdatedAt = updatedAt
 	}
 
-	output.Results = results
-
-	return &output
+	return nil
 }
 
-type getAggregatedFeedOutputResult struct {
-	Activities    []*Activity `json:"activities"`
-	ActivityCount int         `json:"activity_count"`
-	ActorCount    int         `json:"actor_count"`
-	CreatedAt     string      `json:"created_at"`
-	Group         string      `json:"group"`
-	ID            string      `json:"id"`
-	UpdatedAt     string      `json:"updated_at"`
-	Verb          string      `json:"verb"`
-}
-
-type getAggregatedFeedFollowersInput struct {
-	Limit int `json:"limit"`
-	Skip  int `json:"offset"`
+// GetAggregatedFeedOutput is the response from a AggregatedFeed Activities Get Request
+type GetAggregatedFeedOutput struct {
+	Duration string             `json:"duration"`
+	Next     string             `json:"next"`
+	Results  []*AggregatedGroup `json:"results"`
 }
 
 type getAggregatedFeedFollowersOutput struct {
@@ -229,11 +230,11 @@ func (f *AggregatedFeed) AddActivities(activities []*Activity) ([]*Activity, err
 // Activities returns a list of Activities for a NotificationFeedGroup
 func (f *AggregatedFeed) Activities(input *GetAggregatedFeedInput) (*GetAggregatedFeedOutput, error) {
 
-	var payload []byte
+	params := map[string]string{}
 	var err error
 
 	if input != nil {
-		payload, err = json.Marshal(input)
+		params, err = input.params()
 		if err != nil {
 			return nil, err
 		}
@@ -241,18 +242,49 @@ func (f *AggregatedFeed) Activities(input *GetAggregatedFeedInput) (*GetAggregat
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/"
 
-	result, err := f.Client.get(f, endpoint, payload, nil)
+	result, err := f.Client.get(f, endpoint, nil, params)
 	if err != nil {
 		return nil, err
 	}
 
-	output := &getAggregatedFeedOutput{}
+	output := &GetAggregatedFeedOutput{}
 	err = json.Unmarshal(result, output)
 	if err != nil {
 		return nil, err
 	}
 
-	return output.output(), err
+	return output, nil
+}
+
+// EnrichedActivities returns a list of EnrichedActivities for a AggregatedFeedGroup
+// User and collection references are expanded by the API, reactions are included
+// according to the reaction options of the input
+func (f *AggregatedFeed) EnrichedActivities(input *GetAggregatedFeedInput) (*GetEnrichedAggregatedFeedOutput, error) {
+
+	params := map[string]string{}
+	var err error
+
+	if input != nil {
+		params, err = input.params()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := "enrich/feed/" + f.FeedSlug + "/" + f.UserID + "/"
+
+	result, err := f.Client.get(f, endpoint, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetEnrichedAggregatedFeedOutput{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, err
 }
 
 // RemoveActivity removes an Activity from a NotificationFeedGroup
@@ -337,16 +369,14 @@ func (f *AggregatedFeed) FollowersWithLimitAndSkip(limit int, skip int) ([]*Gene
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "followers" + "/"
 
-	payload, err := json.Marshal(&getFlatFeedFollowersInput{
-		Limit: limit,
-		Skip:  skip,
+	resultBytes, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(skip),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resultBytes, err := f.Client.get(f, endpoint, payload, nil)
-
 	output := &getAggregatedFeedFollowersOutput{}
 	err = json.Unmarshal(resultBytes, output)
 	if err != nil {
@@ -384,16 +414,14 @@ func (f *AggregatedFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*Gene
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "following" + "/"
 
-	payload, err := json.Marshal(&getAggregatedFeedFollowersInput{
-		Limit: limit,
-		Skip:  skip,
+	resultBytes, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(skip),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resultBytes, err := f.Client.get(f, endpoint, payload, nil)
-
 	output := &getAggregatedFeedFollowersOutput{}
 	err = json.Unmarshal(resultBytes, output)
 	if err != nil {
@@ -424,3 +452,11 @@ func (f *AggregatedFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*Gene
 	return outputFeeds, err
 
 }
+
+// IsFollowing checks which of the target Feeds are followed by the current AggregatedFeed
+func (f *AggregatedFeed) IsFollowing(targets ...Feed) (map[FeedID]bool, error) {
+
+	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "following" + "/"
+
+	return f.Client.isFollowing(f, endpoint, targets)
+}
@@ -2,6 +2,8 @@ package getstream_test
 
 import (
 	"encoding/json"
+	"net/http"
+	"strings"
 	"testing"
 	"time"
 
@@ -438,3 +440,98 @@ func TestAggregatedActivityMetaData(t *testing.T) {
 		t.Error(string(*activity.Data), string(*resultActivity.Data))
 	}
 }
+
+func TestAggregatedFeedActivitiesQueryParams(t *testing.T) {
+	var requestURL string
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		requestURL = r.URL.String()
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.AggregatedFeed("aggregated", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.Activities(&getstream.GetAggregatedFeedInput{
+		Limit: 10,
+		IDGT:  "abc",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "/api/v1.0/feed/aggregated/bob/?api_key=my_key&id_gt=abc&limit=10&location=unspecified"
+	if requestURL != expected {
+		t.Fatal("Expected request to", expected, "got:", requestURL)
+	}
+}
+
+func TestAggregatedFeedActivitiesGroups(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": [{"id": "g1", "group": "post_2017-05-25", "verb": "post", "activity_count": 2, "actor_count": 1,
+			"created_at": "2017-05-25T12:52:42.571262", "updated_at": "2017-05-25T13:00:00.5",
+			"activities": [{"id": "1", "actor": "bob", "verb": "post", "object": "post:1"}, {"id": "2", "actor": "bob", "verb": "post", "object": "post:2"}]}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.AggregatedFeed("aggregated", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Results) != 1 {
+		t.Fatal("Expected one group, got:", output.Results)
+	}
+
+	var group *getstream.AggregatedGroup = output.Results[0]
+	if group.ID != "g1" || group.Group != "post_2017-05-25" || group.ActivityCount != 2 || group.ActorCount != 1 || len(group.Activities) != 2 {
+		t.Fatal("Unexpected group:", group)
+	}
+	if !group.CreatedAt.Equal(time.Date(2017, 5, 25, 12, 52, 42, 571262000, time.UTC)) {
+		t.Fatal("Unexpected created_at:", group.CreatedAt)
+	}
+	if !group.UpdatedAt.Equal(time.Date(2017, 5, 25, 13, 0, 0, 500000000, time.UTC)) {
+		t.Fatal("Unexpected updated_at:", group.UpdatedAt)
+	}
+}
+
+func TestAggregatedGroupJSON(t *testing.T) {
+	group := &getstream.AggregatedGroup{}
+	err := json.Unmarshal([]byte(`{"id": "g1", "verb": "post", "activity_count": 2, "created_at": "2017-05-25T12:52:42.571262", "updated_at": "bad"}`), group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != "g1" || group.ActivityCount != 2 || !group.CreatedAt.Equal(time.Date(2017, 5, 25, 12, 52, 42, 571262000, time.UTC)) || !group.UpdatedAt.IsZero() {
+		t.Fatal("Unexpected group:", group)
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"created_at":"2017-05-25T12:52:42.571262"`) || !strings.Contains(string(data), `"activity_count":2`) {
+		t.Fatal("Expected the API field names and time format, got:", string(data))
+	}
+
+	decoded := &getstream.AggregatedGroup{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreatedAt.Equal(group.CreatedAt) || decoded.Verb != "post" || decoded.ActivityCount != 2 {
+		t.Fatal("Expected the group to survive a round trip, got:", decoded)
+	}
+}
@@ -22,7 +22,36 @@ type GetFlatFeedInput struct {
 	IDLTE string `json:"id_lte,omitempty"`
 	IDLT  string `json:"id_lt,omitempty"`
 
-	Ranking string `json:"ranking,omitempty"`
+	Ranking             string                 `json:"ranking,omitempty"`
+	RankingVars         map[string]interface{} `json:"ranking_vars,omitempty"`
+	ExternalRankingVars map[string]interface{} `json:"external_ranking_vars,omitempty"`
+	SessionID           string                 `json:"session_id,omitempty"`
+
+	// reaction options, only honoured by EnrichedActivities
+	WithReactionCounts   bool `json:"withReactionCounts,omitempty"`
+	WithOwnReactions     bool `json:"withOwnReactions,omitempty"`
+	WithRecentReactions  bool `json:"withRecentReactions,omitempty"`
+	RecentReactionsLimit int  `json:"recentReactionsLimit,omitempty"`
+}
+
+// params encodes the input as query params for a feed read
+func (i *GetFlatFeedInput) params() (map[string]string, error) {
+	return feedReadParams{
+		Limit:                i.Limit,
+		Offset:               i.Offset,
+		IDGTE:                i.IDGTE,
+		IDGT:                 i.IDGT,
+		IDLTE:                i.IDLTE,
+		IDLT:                 i.IDLT,
+		Ranking:              i.Ranking,
+		RankingVars:          i.RankingVars,
+		ExternalRankingVars:  i.ExternalRankingVars,
+		SessionID:            i.SessionID,
+		WithReactionCounts:   i.WithReactionCounts,
+		WithOwnReactions:     i.WithOwnReactions,
+		WithRecentReactions:  i.WithRecentReactions,
+		RecentReactionsLimit: i.RecentReactionsLimit,
+	}.params()
 }
 
 // GetFlatFeedOutput is the response from a FlatFeed Activities Get Request
@@ -32,11 +61,6 @@ type GetFlatFeedOutput struct {
 	Activities []*Activity `json:"results"`
 }
 
-type getFlatFeedFollowersInput struct {
-	Limit int `json:"limit"`
-	Skip  int `json:"offset"`
-}
-
 type getFlatFeedFollowersOutput struct {
 	Duration string                              `json:"duration"`
 	Results  []*getFlatFeedFollowersOutputResult `json:"results"`
@@ -158,11 +182,11 @@ func (f *FlatFeed) AddActivities(activities []*Activity) ([]*Activity, error) {
 // Activities returns a list of Activities for a FlatFeedGroup
 func (f *FlatFeed) Activities(input *GetFlatFeedInput) (*GetFlatFeedOutput, error) {
 
-	var payload []byte
+	params := map[string]string{}
 	var err error
 
 	if input != nil {
-		payload, err = json.Marshal(input)
+		params, err = input.params()
 		if err != nil {
 			return nil, err
 		}
@@ -170,7 +194,7 @@ func (f *FlatFeed) Activities(input *GetFlatFeedInput) (*GetFlatFeedOutput, erro
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/"
 
-	result, err := f.Client.get(f, endpoint, payload, nil)
+	result, err := f.Client.get(f, endpoint, nil, params)
 	if err != nil {
 		return nil, err
 	}
@@ -184,6 +208,37 @@ func (f *FlatFeed) Activities(input *GetFlatFeedInput) (*GetFlatFeedOutput, erro
 	return output, err
 }
 
+// EnrichedActivities returns a list of EnrichedActivities for a FlatFeedGroup
+// User and collection references are expanded by the API, reactions are included
+// according to the reaction options of the input
+func (f *FlatFeed) EnrichedActivities(input *GetFlatFeedInput) (*GetEnrichedFlatFeedOutput, error) {
+
+	params := map[string]string{}
+	var err error
+
+	if input != nil {
+		params, err = input.params()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := "enrich/feed/" + f.FeedSlug + "/" + f.UserID + "/"
+
+	result, err := f.Client.get(f, endpoint, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetEnrichedFlatFeedOutput{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, err
+}
+
 // RemoveActivity removes an Activity from a FlatFeedGroup
 func (f *FlatFeed) RemoveActivity(input *Activity) error {
 
@@ -263,16 +318,14 @@ func (f *FlatFeed) FollowersWithLimitAndSkip(limit int, skip int) ([]*GeneralFee
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "followers" + "/"
 
-	payload, err := json.Marshal(&getFlatFeedFollowersInput{
-		Limit: limit,
-		Skip:  skip,
+	resultBytes, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(skip),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resultBytes, err := f.Client.get(f, endpoint, payload, nil)
-
 	output := &getFlatFeedFollowersOutput{}
 	err = json.Unmarshal(resultBytes, output)
 	if err != nil {
@@ -310,16 +363,14 @@ func (f *FlatFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*GeneralFee
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "following" + "/"
 
-	payload, err := json.Marshal(&getFlatFeedFollowersInput{
-		Limit: limit,
-		Skip:  skip,
+	resultBytes, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(skip),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resultBytes, err := f.Client.get(f, endpoint, payload, nil)
-
 	output := &getFlatFeedFollowersOutput{}
 	err = json.Unmarshal(resultBytes, output)
 	if err != nil {
@@ -350,6 +401,14 @@ func (f *FlatFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*GeneralFee
 	return outputFeeds, err
 }
 
+// IsFollowing checks which of the target Feeds are followed by the current FlatFeed
+func (f *FlatFeed) IsFollowing(targets ...Feed) (map[FeedID]bool, error) {
+
+	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "following" + "/"
+
+	return f.Client.isFollowing(f, endpoint, targets)
+}
+
 /** FollowFeedsWithCopyLimit sets a Feed to follow one or more other target Feeds
 	This method only exists within FlatFeed because only flat feeds can follow other feeds
 
@@ -361,34 +420,7 @@ func (f *FlatFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*GeneralFee
  	error, if any
 */
 func (f *FlatFeed) FollowManyFeeds(sourceFeeds []PostFlatFeedFollowingManyInput, copyLimit int) error {
-
-	final_payload, err := json.Marshal(sourceFeeds)
-	if err != nil {
-		return err
-	}
-
-	var params = map[string]string{}
-	if copyLimit < 0 {
-		copyLimit = 100
-	}
-	params = map[string]string{
-		"activity_copy_limit": strconv.Itoa(copyLimit),
-	}
-
-	endpoint := "follow_many/"
-
-	//save_token := ""
-	//if f.token != "" {
-	//fmt.Println("saving token for later")
-	//save_token = f.token
-	//f.token = ""
-	//}
-	_, err = f.Client.post(f, endpoint, final_payload, params)
-	//if save_token != "" {
-	//fmt.Println("restoring token")
-	//f.token = save_token
-	//}
-	return err
+	return f.Client.FollowMany(sourceFeeds, copyLimit)
 }
 
 type postMultipleActivities struct {
@@ -2,6 +2,8 @@ package getstream_test
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -633,3 +635,144 @@ func TestFlatFeedUpdateActivities(t *testing.T) {
 		}
 	}
 }
+
+func TestFlatFeedActivitiesQueryParams(t *testing.T) {
+	var query url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.Activities(&getstream.GetFlatFeedInput{
+		Limit:               25,
+		Offset:              50,
+		IDLT:                "e561de8f-00f1-11e4-b400-0cc47a024be0",
+		Ranking:             "popularity",
+		RankingVars:         map[string]interface{}{"boost": 2},
+		ExternalRankingVars: map[string]interface{}{"music": 1.5},
+		SessionID:           "session-1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"limit":                 "25",
+		"offset":                "50",
+		"id_lt":                 "e561de8f-00f1-11e4-b400-0cc47a024be0",
+		"ranking":               "popularity",
+		"ranking_vars":          `{"boost":2}`,
+		"external_ranking_vars": `{"music":1.5}`,
+		"session_id":            "session-1",
+		"api_key":               "my_key",
+		"location":              "unspecified",
+	}
+	for key, value := range expected {
+		if query.Get(key) != value {
+			t.Error("Expected query param", key, "to be", value, "got:", query.Get(key))
+		}
+	}
+	if len(query) != len(expected) {
+		t.Error("Expected", len(expected), "query params, got:", query)
+	}
+}
+
+func TestFlatFeedActivitiesNoInput(t *testing.T) {
+	var rawQuery string
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rawQuery != "api_key=my_key&location=unspecified" {
+		t.Fatal("Expected only the standard query params, got:", rawQuery)
+	}
+}
+
+func TestFlatFeedRankedActivities(t *testing.T) {
+	var query url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results": [{"id": "1", "actor": "bob", "verb": "post", "object": "post:1", "score": 3.25}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := feed.Activities(&getstream.GetFlatFeedInput{
+		Ranking:     "popularity",
+		RankingVars: map[string]interface{}{"decay": "week"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query.Get("ranking") != "popularity" || query.Get("ranking_vars") != `{"decay":"week"}` {
+		t.Fatal("Expected ranking and ranking_vars query params, got:", query)
+	}
+	if len(output.Activities) != 1 || output.Activities[0].Score != 3.25 {
+		t.Fatal("Expected a ranked activity with score 3.25, got:", output.Activities)
+	}
+}
+
+func TestFlatFeedRankedActivitiesInvalidRanking(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected no request for an invalid ranking")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.Activities(&getstream.GetFlatFeedInput{
+		Ranking: "not a ranking",
+	})
+	if err == nil || err.Error() != "invalid ranking" {
+		t.Fatal("Expected invalid ranking error, got:", err)
+	}
+
+	_, err = feed.Activities(&getstream.GetFlatFeedInput{
+		Ranking:     "popularity",
+		RankingVars: map[string]interface{}{"bad name": 1},
+	})
+	if err == nil || err.Error() != "invalid ranking variable bad name" {
+		t.Fatal("Expected invalid ranking variable error, got:", err)
+	}
+}
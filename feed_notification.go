@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type postNotificationFeedOutputActivities struct {
@@ -22,112 +23,125 @@ type GetNotificationFeedInput struct {
 	IDLTE string `json:"id_lte,omitempty"`
 	IDLT  string `json:"id_lt,omitempty"`
 
-	Ranking string `json:"ranking,omitempty"`
+	Ranking             string                 `json:"ranking,omitempty"`
+	RankingVars         map[string]interface{} `json:"ranking_vars,omitempty"`
+	ExternalRankingVars map[string]interface{} `json:"external_ranking_vars,omitempty"`
+	SessionID           string                 `json:"session_id,omitempty"`
+
+	// MarkRead and MarkSeen mark all groups, MarkReadIDs and MarkSeenIDs only the given group ids,
+	// setting both MarkRead and MarkReadIDs (or MarkSeen and MarkSeenIDs) is an error
+	MarkRead    bool     `json:"-"`
+	MarkReadIDs []string `json:"-"`
+	MarkSeen    bool     `json:"-"`
+	MarkSeenIDs []string `json:"-"`
+
+	// reaction options, only honoured by EnrichedActivities
+	WithReactionCounts   bool `json:"withReactionCounts,omitempty"`
+	WithOwnReactions     bool `json:"withOwnReactions,omitempty"`
+	WithRecentReactions  bool `json:"withRecentReactions,omitempty"`
+	RecentReactionsLimit int  `json:"recentReactionsLimit,omitempty"`
 }
 
-// GetNotificationFeedOutput is the response from a NotificationFeed Activities Get Request
-type GetNotificationFeedOutput struct {
-	Duration string
-	Next     string
-	Results  []*struct {
-		Activities    []*Activity
-		ActivityCount int
-		ActorCount    int
-		CreatedAt     string
-		Group         string
-		ID            string
-		IsRead        bool
-		IsSeen        bool
-		UpdatedAt     string
-		Verb          string
-	}
-	Unread int
-	Unseen int
-}
-
-type getNotificationFeedOutput struct {
-	Duration string                             `json:"duration"`
-	Next     string                             `json:"next"`
-	Results  []*getNotificationFeedOutputResult `json:"results"`
-	Unread   int                                `json:"unread"`
-	Unseen   int                                `json:"unseen"`
-}
-
-func (a getNotificationFeedOutput) output() *GetNotificationFeedOutput {
-
-	output := GetNotificationFeedOutput{
-		Duration: a.Duration,
-		Next:     a.Next,
-		Unread:   a.Unread,
-		Unseen:   a.Unseen,
-	}
-
-	var results []*struct {
-		Activities    []*Activity
-		ActivityCount int
-		ActorCount    int
-		CreatedAt     string
-		Group         string
-		ID            string
-		IsRead        bool
-		IsSeen        bool
-		UpdatedAt     string
-		Verb          string
-	}
-
-	for _, result := range a.Results {
-
-		outputResult := struct {
-			Activities    []*Activity
-			ActivityCount int
-			ActorCount    int
-			CreatedAt     string
-			Group         string
-			ID            string
-			IsRead        bool
-			IsSeen        bool
-			UpdatedAt     string
-			Verb          string
-		}{
-			ActivityCount: result.ActivityCount,
-			ActorCount:    result.ActorCount,
-			CreatedAt:     result.CreatedAt,
-			Group:         result.Group,
-			ID:            result.ID,
-			IsRead:        result.IsRead,
-			IsSeen:        result.IsSeen,
-			UpdatedAt:     result.UpdatedAt,
-			Verb:          result.Verb,
-		}
-
-		for _, activity := range result.Activities {
-			outputResult.Activities = append(outputResult.Activities, activity)
-		}
+// params encodes the input as query params for a feed read
+func (i *GetNotificationFeedInput) params() (map[string]string, error) {
+	params, err := feedReadParams{
+		Limit:                i.Limit,
+		Offset:               i.Offset,
+		IDGTE:                i.IDGTE,
+		IDGT:                 i.IDGT,
+		IDLTE:                i.IDLTE,
+		IDLT:                 i.IDLT,
+		Ranking:              i.Ranking,
+		RankingVars:          i.RankingVars,
+		ExternalRankingVars:  i.ExternalRankingVars,
+		SessionID:            i.SessionID,
+		WithReactionCounts:   i.WithReactionCounts,
+		WithOwnReactions:     i.WithOwnReactions,
+		WithRecentReactions:  i.WithRecentReactions,
+		RecentReactionsLimit: i.RecentReactionsLimit,
+	}.params()
+	if err != nil {
+		return nil, err
+	}
 
-		results = append(results, &outputResult)
+	if i.MarkRead && len(i.MarkReadIDs) > 0 {
+		return nil, errors.New("MarkRead and MarkReadIDs can't both be set")
+	}
+	if i.MarkSeen && len(i.MarkSeenIDs) > 0 {
+		return nil, errors.New("MarkSeen and MarkSeenIDs can't both be set")
 	}
 
-	output.Results = results
+	if markRead := markParam(i.MarkRead, i.MarkReadIDs); markRead != "" {
+		params["mark_read"] = markRead
+	}
+	if markSeen := markParam(i.MarkSeen, i.MarkSeenIDs); markSeen != "" {
+		params["mark_seen"] = markSeen
+	}
 
-	return &output
+	return params, nil
 }
 
-type getNotificationFeedOutputResult struct {
+// NotificationGroup is a group of Activities returned from a NotificationFeed
+type NotificationGroup struct {
 	Activities    []*Activity `json:"activities"`
 	ActivityCount int         `json:"activity_count"`
 	ActorCount    int         `json:"actor_count"`
-	CreatedAt     string      `json:"created_at"`
+	CreatedAt     time.Time   `json:"created_at"`
 	Group         string      `json:"group"`
 	ID            string      `json:"id"`
 	IsRead        bool        `json:"is_read"`
 	IsSeen        bool        `json:"is_seen"`
-	UpdatedAt     string      `json:"updated_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
 	Verb          string      `json:"verb"`
 }
 
-type getNotificationFeedFollowersInput struct {
-	Limit int `json:"limit"`
-	Skip  int `json:"offset"`
+// MarshalJSON is the custom marshal function for NotificationGroups
+// It writes the timestamps in the format of the API, which UnmarshalJSON reads back
+func (g NotificationGroup) MarshalJSON() ([]byte, error) {
+	type group NotificationGroup
+	return json.Marshal(struct {
+		group
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{
+		group:     group(g),
+		CreatedAt: formatTime(g.CreatedAt),
+		UpdatedAt: formatTime(g.UpdatedAt),
+	})
+}
+
+// UnmarshalJSON is the custom unmarshal function for NotificationGroups
+// Timestamps the API sends in an unknown format are left zero
+func (g *NotificationGroup) UnmarshalJSON(b []byte) error {
+	type group NotificationGroup
+	payload := struct {
+		*group
+		CreatedAt string `json:"created_at"`
+		UpdatedAt string `json:"updated_at"`
+	}{group: (*group)(g)}
+
+	err := json.Unmarshal(b, &payload)
+	if err != nil {
+		return err
+	}
+
+	if createdAt, err := parseTime(payload.CreatedAt); err == nil {
+		g.CreatedAt = createdAt
+	}
+	if updatedAt, err := parseTime(payload.UpdatedAt); err == nil {
+		g.UpdatedAt = updatedAt
+	}
+
+	return nil
+}
+
+// GetNotificationFeedOutput is the response from a NotificationFeed Activities Get Request
+type GetNotificationFeedOutput struct {
+	Duration string               `json:"duration"`
+	Next     string               `json:"next"`
+	Results  []*NotificationGroup `json:"results"`
+	Unread   int                  `json:"unread"`
+	Unseen   int                  `json:"unseen"`
 }
 
 type getNotificationFeedFollowersOutput struct {
@@ -243,7 +257,15 @@ func (f *NotificationFeed) AddActivities(activities []*Activity) ([]*Activity, e
 	return output.Activities, err
 }
 
+// NotificationFeedCounts holds the unread and unseen counters of a NotificationFeed
+type NotificationFeedCounts struct {
+	Unread int `json:"unread"`
+	Unseen int `json:"unseen"`
+}
+
 // MarkActivitiesAsRead marks activities as read for this feed
+//
+// Deprecated: the API expects group ids rather than activity ids, use MarkRead instead
 func (f *NotificationFeed) MarkActivitiesAsRead(activities []*Activity) error {
 
 	var ids []string
@@ -263,6 +285,8 @@ func (f *NotificationFeed) MarkActivitiesAsRead(activities []*Activity) error {
 }
 
 // MarkActivitiesAsSeenWithLimit marks activities as seen for this feed
+//
+// Deprecated: use MarkAllSeen or MarkSeen instead
 func (f *NotificationFeed) MarkActivitiesAsSeenWithLimit(limit int) error {
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/"
@@ -275,14 +299,78 @@ func (f *NotificationFeed) MarkActivitiesAsSeenWithLimit(limit int) error {
 	return err
 }
 
+// MarkRead marks the given activity groups as read and returns the refreshed counters
+func (f *NotificationFeed) MarkRead(groupIDs ...string) (*NotificationFeedCounts, error) {
+	if len(groupIDs) == 0 {
+		return nil, errors.New("No group ids to mark as read")
+	}
+	return f.mark("mark_read", groupIDs)
+}
+
+// MarkSeen marks the given activity groups as seen and returns the refreshed counters
+func (f *NotificationFeed) MarkSeen(groupIDs ...string) (*NotificationFeedCounts, error) {
+	if len(groupIDs) == 0 {
+		return nil, errors.New("No group ids to mark as seen")
+	}
+	return f.mark("mark_seen", groupIDs)
+}
+
+// MarkAllRead marks all activity groups as read and returns the refreshed counters
+func (f *NotificationFeed) MarkAllRead() (*NotificationFeedCounts, error) {
+	return f.mark("mark_read", nil)
+}
+
+// MarkAllSeen marks all activity groups as seen and returns the refreshed counters
+func (f *NotificationFeed) MarkAllSeen() (*NotificationFeedCounts, error) {
+	return f.mark("mark_seen", nil)
+}
+
+// Counts returns the unread and unseen counters without the activities, e.g. for rendering badges
+func (f *NotificationFeed) Counts() (*NotificationFeedCounts, error) {
+
+	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/"
+
+	result, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit": "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &NotificationFeedCounts{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, err
+}
+
+// mark sends a mark_read or mark_seen request for the group ids, or for all groups when there are none
+// The request has no limit, so that it doesn't restrict the groups marked; the counters in its response
+// are from before marking, so they are read again afterwards
+func (f *NotificationFeed) mark(param string, groupIDs []string) (*NotificationFeedCounts, error) {
+
+	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/"
+
+	_, err := f.Client.get(f, endpoint, nil, map[string]string{
+		param: markParam(len(groupIDs) == 0, groupIDs),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return f.Counts()
+}
+
 // Activities returns a list of Activities for a NotificationFeedGroup
 func (f *NotificationFeed) Activities(input *GetNotificationFeedInput) (*GetNotificationFeedOutput, error) {
 
-	var payload []byte
+	params := map[string]string{}
 	var err error
 
 	if input != nil {
-		payload, err = json.Marshal(input)
+		params, err = input.params()
 		if err != nil {
 			return nil, err
 		}
@@ -290,18 +378,49 @@ func (f *NotificationFeed) Activities(input *GetNotificationFeedInput) (*GetNoti
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/"
 
-	result, err := f.Client.get(f, endpoint, payload, nil)
+	result, err := f.Client.get(f, endpoint, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetNotificationFeedOutput{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// EnrichedActivities returns a list of EnrichedActivities for a NotificationFeedGroup
+// User and collection references are expanded by the API, reactions are included
+// according to the reaction options of the input
+func (f *NotificationFeed) EnrichedActivities(input *GetNotificationFeedInput) (*GetEnrichedNotificationFeedOutput, error) {
+
+	params := map[string]string{}
+	var err error
+
+	if input != nil {
+		params, err = input.params()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	endpoint := "enrich/feed/" + f.FeedSlug + "/" + f.UserID + "/"
+
+	result, err := f.Client.get(f, endpoint, nil, params)
 	if err != nil {
 		return nil, err
 	}
 
-	output := &getNotificationFeedOutput{}
+	output := &GetEnrichedNotificationFeedOutput{}
 	err = json.Unmarshal(result, output)
 	if err != nil {
 		return nil, err
 	}
 
-	return output.output(), err
+	return output, err
 }
 
 // RemoveActivity removes an Activity from a NotificationFeedGroup
@@ -387,16 +506,14 @@ func (f *NotificationFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*Ge
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "following" + "/"
 
-	payload, err := json.Marshal(&getNotificationFeedFollowersInput{
-		Limit: limit,
-		Skip:  skip,
+	resultBytes, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(skip),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resultBytes, err := f.Client.get(f, endpoint, payload, nil)
-
 	output := &getNotificationFeedFollowersOutput{}
 	err = json.Unmarshal(resultBytes, output)
 	if err != nil {
@@ -428,22 +545,28 @@ func (f *NotificationFeed) FollowingWithLimitAndSkip(limit int, skip int) ([]*Ge
 
 }
 
+// IsFollowing checks which of the target Feeds are followed by the current NotificationFeed
+func (f *NotificationFeed) IsFollowing(targets ...Feed) (map[FeedID]bool, error) {
+
+	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "following" + "/"
+
+	return f.Client.isFollowing(f, endpoint, targets)
+}
+
 // FollowersWithLimitAndSkip returns a list of GeneralFeed following the current FlatFeed
 func (f *NotificationFeed) FollowersWithLimitAndSkip(limit int, skip int) ([]*GeneralFeed, error) {
 	var err error
 
 	endpoint := "feed/" + f.FeedSlug + "/" + f.UserID + "/" + "followers" + "/"
 
-	payload, err := json.Marshal(&getFlatFeedFollowersInput{
-		Limit: limit,
-		Skip:  skip,
+	resultBytes, err := f.Client.get(f, endpoint, nil, map[string]string{
+		"limit":  strconv.Itoa(limit),
+		"offset": strconv.Itoa(skip),
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	resultBytes, err := f.Client.get(f, endpoint, payload, nil)
-
 	output := &getFlatFeedFollowersOutput{}
 	err = json.Unmarshal(resultBytes, output)
 	if err != nil {
@@ -2,6 +2,9 @@ package getstream_test
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -512,3 +515,228 @@ func TestNotificationActivityMetaData(t *testing.T) {
 		t.Error(string(*activity.Data), string(*resultActivity.Data))
 	}
 }
+
+func TestNotificationFeedActivitiesQueryParams(t *testing.T) {
+	var query url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.Activities(&getstream.GetNotificationFeedInput{
+		Limit:       5,
+		IDGTE:       "abc",
+		MarkReadIDs: []string{"group1", "group2"},
+		MarkSeen:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"limit":     "5",
+		"id_gte":    "abc",
+		"mark_read": "group1,group2",
+		"mark_seen": "true",
+	}
+	for key, value := range expected {
+		if query.Get(key) != value {
+			t.Error("Expected query param", key, "to be", value, "got:", query.Get(key))
+		}
+	}
+
+	_, err = feed.Activities(&getstream.GetNotificationFeedInput{
+		MarkRead:    true,
+		MarkReadIDs: []string{"group1"},
+	})
+	if err == nil {
+		t.Fatal("Expected an error when both MarkRead and MarkReadIDs are set")
+	}
+}
+
+func TestNotificationFeedMarkRead(t *testing.T) {
+	var queries []url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		if r.URL.Query().Get("mark_read") != "" {
+			w.Write([]byte(`{"unread": 5, "unseen": 5, "results": []}`))
+			return
+		}
+		w.Write([]byte(`{"unread": 4, "unseen": 5, "results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// g99 isn't on the first page, the counters come from the server all the same
+	counts, err := feed.MarkRead("g99")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatal("Expected a mark request and a counts request, got:", queries)
+	}
+	if queries[0].Get("mark_read") != "g99" || queries[0].Get("limit") != "" {
+		t.Fatal("Expected mark_read=g99 without a limit, got:", queries[0])
+	}
+	if queries[1].Get("mark_read") != "" || queries[1].Get("limit") != "1" {
+		t.Fatal("Expected a plain counts request, got:", queries[1])
+	}
+	if counts.Unread != 4 || counts.Unseen != 5 {
+		t.Fatal("Expected refreshed counters, got:", counts)
+	}
+
+	_, err = feed.MarkRead()
+	if err == nil {
+		t.Fatal("Expected an error when marking no groups")
+	}
+}
+
+func TestNotificationFeedMarkAll(t *testing.T) {
+	var queries []url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		w.Write([]byte(`{"unread": 0, "unseen": 0, "results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.MarkAllSeen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.MarkAllRead()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.MarkSeen("group1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(queries) != 6 {
+		t.Fatal("Expected 6 requests, got:", len(queries))
+	}
+	if queries[0].Get("mark_seen") != "true" || queries[0].Get("limit") != "" {
+		t.Fatal("Expected mark_seen=true without a limit, got:", queries[0])
+	}
+	if queries[2].Get("mark_read") != "true" {
+		t.Fatal("Expected mark_read=true, got:", queries[2])
+	}
+	if queries[4].Get("mark_seen") != "group1" {
+		t.Fatal("Expected mark_seen=group1, got:", queries[4])
+	}
+}
+
+func TestNotificationFeedCounts(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"unread": 4, "unseen": 7, "results": [{"id": "g1", "activities": []}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := feed.Counts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts.Unread != 4 || counts.Unseen != 7 {
+		t.Fatal("Expected unread 4 and unseen 7, got:", counts)
+	}
+}
+
+func TestNotificationFeedActivitiesGroups(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"unread": 1, "unseen": 0, "results": [{"id": "g1", "group": "like", "verb": "like", "is_read": false, "is_seen": true,
+			"activity_count": 1, "actor_count": 1, "created_at": "2017-05-25T12:52:42.571262", "updated_at": "bad",
+			"activities": [{"id": "1", "actor": "bob", "verb": "like", "object": "post:1"}]}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.NotificationFeed("notification", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(output.Results) != 1 {
+		t.Fatal("Expected one group, got:", output.Results)
+	}
+
+	var group *getstream.NotificationGroup = output.Results[0]
+	if group.ID != "g1" || group.IsRead || !group.IsSeen || len(group.Activities) != 1 {
+		t.Fatal("Unexpected group:", group)
+	}
+	if !group.CreatedAt.Equal(time.Date(2017, 5, 25, 12, 52, 42, 571262000, time.UTC)) {
+		t.Fatal("Unexpected created_at:", group.CreatedAt)
+	}
+	if !group.UpdatedAt.IsZero() {
+		t.Fatal("Expected an unparseable updated_at to be left empty, got:", group.UpdatedAt)
+	}
+}
+
+func TestNotificationGroupJSON(t *testing.T) {
+	group := &getstream.NotificationGroup{}
+	err := json.Unmarshal([]byte(`{"id": "g1", "verb": "like", "is_read": true, "created_at": "2017-05-25T12:52:42.571262", "updated_at": "2017-05-26T08:00:00"}`), group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if group.ID != "g1" || !group.IsRead || group.IsSeen || !group.UpdatedAt.Equal(time.Date(2017, 5, 26, 8, 0, 0, 0, time.UTC)) {
+		t.Fatal("Unexpected group:", group)
+	}
+
+	data, err := json.Marshal(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"updated_at":"2017-05-26T08:00:00"`) || !strings.Contains(string(data), `"is_read":true`) {
+		t.Fatal("Expected the API field names and time format, got:", string(data))
+	}
+
+	decoded := &getstream.NotificationGroup{}
+	err = json.Unmarshal(data, decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreatedAt.Equal(group.CreatedAt) || !decoded.UpdatedAt.Equal(group.UpdatedAt) || !decoded.IsRead || decoded.Verb != "like" {
+		t.Fatal("Expected the group to survive a round trip, got:", decoded)
+	}
+}
@@ -0,0 +1,101 @@
+package getstream
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// feedReadParams holds the read options shared by all Feed types
+type feedReadParams struct {
+	Limit  int
+	Offset int
+
+	IDGTE string
+	IDGT  string
+	IDLTE string
+	IDLT  string
+
+	Ranking             string
+	RankingVars         map[string]interface{}
+	ExternalRankingVars map[string]interface{}
+	SessionID           string
+
+	WithReactionCounts   bool
+	WithOwnReactions     bool
+	WithRecentReactions  bool
+	RecentReactionsLimit int
+}
+
+// params encodes the read options as query params, zero values are left out
+func (p feedReadParams) params() (map[string]string, error) {
+	params := map[string]string{}
+
+	if p.Limit > 0 {
+		params["limit"] = strconv.Itoa(p.Limit)
+	}
+	if p.Offset > 0 {
+		params["offset"] = strconv.Itoa(p.Offset)
+	}
+
+	if p.IDGTE != "" {
+		params["id_gte"] = p.IDGTE
+	}
+	if p.IDGT != "" {
+		params["id_gt"] = p.IDGT
+	}
+	if p.IDLTE != "" {
+		params["id_lte"] = p.IDLTE
+	}
+	if p.IDLT != "" {
+		params["id_lt"] = p.IDLT
+	}
+
+	if p.Ranking != "" {
+		ranking, err := ValidateRanking(p.Ranking)
+		if err != nil {
+			return nil, err
+		}
+		params["ranking"] = ranking
+	}
+	if len(p.RankingVars) > 0 {
+		for name := range p.RankingVars {
+			if _, err := ValidateRanking(name); err != nil {
+				return nil, errors.New("invalid ranking variable " + name)
+			}
+		}
+		rankingVars, err := json.Marshal(p.RankingVars)
+		if err != nil {
+			return nil, err
+		}
+		params["ranking_vars"] = string(rankingVars)
+	}
+	if len(p.ExternalRankingVars) > 0 {
+		externalRankingVars, err := json.Marshal(p.ExternalRankingVars)
+		if err != nil {
+			return nil, err
+		}
+		params["external_ranking_vars"] = string(externalRankingVars)
+	}
+	if p.SessionID != "" {
+		params["session_id"] = p.SessionID
+	}
+
+	for key, value := range enrichParams(p.WithReactionCounts, p.WithOwnReactions, p.WithRecentReactions, p.RecentReactionsLimit) {
+		params[key] = value
+	}
+
+	return params, nil
+}
+
+// markParam encodes a mark_read/mark_seen value: a list of group ids, or "true" for all groups
+func markParam(all bool, groupIDs []string) string {
+	if len(groupIDs) > 0 {
+		return strings.Join(groupIDs, ",")
+	}
+	if all {
+		return "true"
+	}
+	return ""
+}
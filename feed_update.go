@@ -0,0 +1,9 @@
+package getstream
+
+// FeedUpdate is a change to a Feed pushed by GetStream.io
+// New holds the added Activities, Deleted the ids of the removed Activities
+type FeedUpdate struct {
+	Feed    FeedID      `json:"feed"`
+	New     []*Activity `json:"new"`
+	Deleted []string    `json:"deleted"`
+}
@@ -0,0 +1,122 @@
+package getstream
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// FollowStats holds the number of followers and followed feeds of a Feed
+type FollowStats struct {
+	Followers int
+	Following int
+}
+
+type getFollowStatsOutput struct {
+	Duration string `json:"duration"`
+	Results  struct {
+		Followers struct {
+			Feed  string `json:"feed"`
+			Count int    `json:"count"`
+		} `json:"followers"`
+		Following struct {
+			Feed  string `json:"feed"`
+			Count int    `json:"count"`
+		} `json:"following"`
+	} `json:"results"`
+}
+
+// FollowStats returns the number of followers and followed feeds of a Feed
+func (c *Client) FollowStats(feed Feed) (*FollowStats, error) {
+	return c.FollowStatsWithSlugs(feed, nil, nil)
+}
+
+// FollowStatsWithSlugs returns the number of followers and followed feeds of a Feed
+// only counting follower feeds of the followerSlugs groups and followed feeds of the followingSlugs groups
+// nil or empty slugs count all feed groups
+func (c *Client) FollowStatsWithSlugs(feed Feed, followerSlugs []string, followingSlugs []string) (*FollowStats, error) {
+	params := map[string]string{
+		"followers": feed.FeedID().Value(),
+		"following": feed.FeedID().Value(),
+	}
+
+	if len(followerSlugs) > 0 {
+		slugs, err := validateFeedSlugs(followerSlugs)
+		if err != nil {
+			return nil, err
+		}
+		params["followers_slugs"] = slugs
+	}
+	if len(followingSlugs) > 0 {
+		slugs, err := validateFeedSlugs(followingSlugs)
+		if err != nil {
+			return nil, err
+		}
+		params["following_slugs"] = slugs
+	}
+
+	result, err := c.get(feed, "stats/follow/", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &getFollowStatsOutput{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FollowStats{
+		Followers: output.Results.Followers.Count,
+		Following: output.Results.Following.Count,
+	}, nil
+}
+
+// validateFeedSlugs validates a list of feed slugs and joins them for a query param
+func validateFeedSlugs(feedSlugs []string) (string, error) {
+	var validated []string
+	for _, feedSlug := range feedSlugs {
+		feedSlug, err := ValidateFeedSlug(feedSlug)
+		if err != nil {
+			return "", err
+		}
+		validated = append(validated, feedSlug)
+	}
+	return strings.Join(validated, ","), nil
+}
+
+// isFollowing checks which of the targets are followed, using the filter of the following endpoint
+func (c *Client) isFollowing(f Feed, endpoint string, targets []Feed) (map[FeedID]bool, error) {
+	result := make(map[FeedID]bool)
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	var filter []string
+	for _, target := range targets {
+		result[target.FeedID()] = false
+		filter = append(filter, target.FeedID().Value())
+	}
+
+	resultBytes, err := c.get(f, endpoint, nil, map[string]string{
+		"filter": strings.Join(filter, ","),
+		"limit":  strconv.Itoa(len(filter)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &getFlatFeedFollowersOutput{}
+	err = json.Unmarshal(resultBytes, output)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, following := range output.Results {
+		if _, ok := result[FeedID(following.TargetID)]; ok {
+			result[FeedID(following.TargetID)] = true
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,121 @@
+package getstream_test
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestClientFollowStats(t *testing.T) {
+	var request *http.Request
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		w.Write([]byte(`{"duration": "1ms", "results": {"followers": {"feed": "flat:bob", "count": 12}, "following": {"feed": "flat:bob", "count": 3}}}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := client.FollowStats(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Followers != 12 || stats.Following != 3 {
+		t.Fatal("Unexpected stats:", stats)
+	}
+
+	if request.URL.Path != "/api/v1.0/stats/follow/" {
+		t.Fatal("Unexpected path:", request.URL.Path)
+	}
+	query := request.URL.Query()
+	if query.Get("followers") != "flat:bob" || query.Get("following") != "flat:bob" || query.Get("followers_slugs") != "" {
+		t.Fatal("Unexpected query:", query)
+	}
+	if request.Header.Get("stream-auth-type") != "jwt" {
+		t.Fatal("Expected jwt auth, got:", request.Header.Get("stream-auth-type"))
+	}
+	claims := tokenClaims(request.Header.Get("Authorization"))
+	if claims["resource"] != "follower" || claims["action"] != "read" {
+		t.Fatal("Expected a follower read token, got:", claims)
+	}
+}
+
+func TestClientFollowStatsWithSlugs(t *testing.T) {
+	var query url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results": {"followers": {"count": 1}, "following": {"count": 2}}}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	followerSlugs := []string{"timeline", "time-line"}
+	_, err = client.FollowStatsWithSlugs(feed, followerSlugs, []string{"user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("followers_slugs") != "timeline,time_line" || query.Get("following_slugs") != "user" {
+		t.Fatal("Unexpected query:", query)
+	}
+	if followerSlugs[1] != "time-line" {
+		t.Fatal("Expected the slugs passed in to be left alone, got:", followerSlugs)
+	}
+
+	_, err = client.FollowStatsWithSlugs(feed, []string{"not valid"}, nil)
+	if err == nil {
+		t.Fatal("Expected an error for an invalid slug")
+	}
+}
+
+func TestFlatFeedIsFollowing(t *testing.T) {
+	var query url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"results": [{"feed_id": "timeline:bob", "target_id": "flat:alice"}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("timeline", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := client.FlatFeed("flat", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	eve, err := client.FlatFeed("flat", "eve")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	following, err := feed.IsFollowing(alice, eve)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query.Get("filter") != "flat:alice,flat:eve" || query.Get("limit") != "2" {
+		t.Fatal("Unexpected query:", query)
+	}
+	if len(following) != 2 || !following["flat:alice"] || following["flat:eve"] {
+		t.Fatal("Expected flat:alice to be followed and flat:eve not, got:", following)
+	}
+}
@@ -1,7 +1,11 @@
 package getstream_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 
 	getstream "github.com/GetStream/stream-go"
 )
@@ -30,6 +34,40 @@ func doTestSetup(cfg *getstream.Config) (*getstream.Client, error) {
 	return getstream.New(cfg)
 }
 
+// PreTestSetupWithServer returns a client which sends its requests to a local test server
+// The feed, analytics, personalization and realtime requests all go to the server, under the
+// paths /api/v1.0/, /analytics/v1.0/, /personalization/v1.0/ and /faye
+// The caller is responsible for closing the server
+func PreTestSetupWithServer(handler http.HandlerFunc) (*getstream.Client, *httptest.Server, error) {
+	server := httptest.NewServer(handler)
+
+	client, err := doTestSetup(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		server.Close()
+		return nil, nil, err
+	}
+
+	urls := map[**url.URL]string{
+		&client.BaseURL:            server.URL + "/api/v1.0/",
+		&client.AnalyticsURL:       server.URL + "/analytics/v1.0/",
+		&client.PersonalizationURL: server.URL + "/personalization/v1.0/",
+		&client.RealtimeURL:        "ws" + strings.TrimPrefix(server.URL, "http") + "/faye",
+	}
+	for field, rawURL := range urls {
+		*field, err = url.Parse(rawURL)
+		if err != nil {
+			server.Close()
+			return nil, nil, err
+		}
+	}
+
+	return client, server, nil
+}
+
 func PostTestCleanUp(
 	client *getstream.Client,
 	flats []*getstream.Activity,
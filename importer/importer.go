@@ -0,0 +1,381 @@
+// Package importer loads activities and follow relationships into GetStream.io
+// from newline delimited JSON, one Record per line:
+//
+//	{"type": "activity", "feed": "user:bob", "activity": {"actor": "bob", "verb": "post", "object": "post:1", "foreign_id": "post:1"}}
+//	{"type": "follow", "source": "timeline:bob", "target": "user:alice"}
+//
+// Consecutive activities of the same feed are added in batches, consecutive follows
+// are created in batches with follow_many. Records which fail validation are written to
+// the rejects file, and the position of the last imported record is kept in a checkpoint
+// file so an interrupted import can be resumed.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+// Record types
+const (
+	RecordActivity = "activity"
+	RecordFollow   = "follow"
+)
+
+// Record is a single line of import input
+type Record struct {
+	Type string `json:"type"`
+
+	// Feed and Activity are used by activity records
+	Feed     string              `json:"feed,omitempty"`
+	Activity *getstream.Activity `json:"activity,omitempty"`
+
+	// Source follows Target, used by follow records
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+// Reject is written to the rejects file for every record which could not be imported
+type Reject struct {
+	Line   int              `json:"line"`
+	Error  string           `json:"error"`
+	Record *json.RawMessage `json:"record"`
+}
+
+// Progress reports how far an import got
+// Line is the last input line handled, including lines skipped because of the checkpoint
+type Progress struct {
+	Line       int
+	Activities int
+	Follows    int
+	Rejected   int
+}
+
+// Config configures an Importer
+type Config struct {
+	// BatchSize is the number of activities added per request, defaults to 100 (the API maximum)
+	BatchSize int
+	// FollowBatchSize is the number of follows created per request, defaults to 100
+	FollowBatchSize int
+	// CopyLimit is the number of activities copied into the source feed for every follow
+	CopyLimit int
+
+	// CheckpointPath is the file the import position is saved in, empty disables checkpoints
+	CheckpointPath string
+	// RejectsPath is the file rejected records are appended to, empty discards them
+	RejectsPath string
+
+	// OnProgress is called after every batch
+	OnProgress func(progress Progress)
+}
+
+// Importer imports Records read from NDJSON
+type Importer struct {
+	client *getstream.Client
+	config Config
+}
+
+type checkpoint struct {
+	Line int `json:"line"`
+}
+
+// New returns an Importer writing to the application of the Client
+func New(client *getstream.Client, cfg *Config) *Importer {
+	config := Config{}
+	if cfg != nil {
+		config = *cfg
+	}
+	if config.BatchSize <= 0 || config.BatchSize > 100 {
+		config.BatchSize = 100
+	}
+	if config.FollowBatchSize <= 0 {
+		config.FollowBatchSize = 100
+	}
+	if config.CopyLimit < 0 {
+		config.CopyLimit = 0
+	}
+
+	return &Importer{
+		client: client,
+		config: config,
+	}
+}
+
+// ImportFile imports the NDJSON file at path
+func (i *Importer) ImportFile(path string) (Progress, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return Progress{}, err
+	}
+	defer file.Close()
+
+	return i.Import(file)
+}
+
+// Import reads Records from r and imports them
+// Lines before the saved checkpoint are skipped. An API error stops the import, records
+// of the failed batch are not part of the checkpoint and are retried when resuming
+func (i *Importer) Import(r io.Reader) (Progress, error) {
+	progress := Progress{}
+
+	start, err := i.readCheckpoint()
+	if err != nil {
+		return progress, err
+	}
+
+	var rejects io.Writer = ioutil.Discard
+	if i.config.RejectsPath != "" {
+		file, err := os.OpenFile(i.config.RejectsPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return progress, err
+		}
+		defer file.Close()
+		rejects = file
+	}
+
+	var batchFeed *getstream.FlatFeed
+	var activities []*getstream.Activity
+	var follows []getstream.PostFlatFeedFollowingManyInput
+	line := 0
+
+	// flush imports the pending batch and moves the checkpoint to the current line
+	flush := func(done int) error {
+		if len(activities) > 0 {
+			_, err := batchFeed.AddActivities(activities)
+			if err != nil {
+				return err
+			}
+			progress.Activities += len(activities)
+			activities = nil
+		}
+		if len(follows) > 0 {
+			err := i.client.FollowMany(follows, i.config.CopyLimit)
+			if err != nil {
+				return err
+			}
+			progress.Follows += len(follows)
+			follows = nil
+		}
+
+		progress.Line = done
+		if err := i.writeCheckpoint(done); err != nil {
+			return err
+		}
+		if i.config.OnProgress != nil {
+			i.config.OnProgress(progress)
+		}
+		return nil
+	}
+
+	// reject records a line which can't be imported, lines which aren't JSON are kept as a string
+	reject := func(raw []byte, reason error) error {
+		progress.Rejected++
+		record := json.RawMessage(raw)
+		if _, ok := reason.(*json.SyntaxError); ok {
+			record, _ = json.Marshal(string(raw))
+		}
+		payload, err := json.Marshal(&Reject{
+			Line:   line,
+			Error:  reason.Error(),
+			Record: &record,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = rejects.Write(append(payload, '\n'))
+		return err
+	}
+
+	reader := bufio.NewReader(r)
+	for {
+		raw, err := reader.ReadBytes('\n')
+		if err == io.EOF && len(raw) == 0 {
+			break
+		}
+		if err != nil && err != io.EOF {
+			return progress, err
+		}
+
+		line++
+		if line <= start {
+			progress.Line = line
+			continue
+		}
+
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 {
+			continue
+		}
+
+		record := &Record{}
+		if err := json.Unmarshal(raw, record); err != nil {
+			if err := reject(raw, err); err != nil {
+				return progress, err
+			}
+			continue
+		}
+
+		switch record.Type {
+		case RecordActivity:
+			feed, err := i.activityFeed(record)
+			if err != nil {
+				if err := reject(raw, err); err != nil {
+					return progress, err
+				}
+				continue
+			}
+
+			// a batch holds activities of a single feed, in input order
+			if len(follows) > 0 || (batchFeed != nil && batchFeed.FeedID() != feed.FeedID()) {
+				if err := flush(line - 1); err != nil {
+					return progress, err
+				}
+			}
+			batchFeed = feed
+			record.Activity.ID = ""
+			activities = append(activities, record.Activity)
+
+			if len(activities) >= i.config.BatchSize {
+				if err := flush(line); err != nil {
+					return progress, err
+				}
+			}
+
+		case RecordFollow:
+			follow, err := followInput(record)
+			if err != nil {
+				if err := reject(raw, err); err != nil {
+					return progress, err
+				}
+				continue
+			}
+
+			if len(activities) > 0 {
+				if err := flush(line - 1); err != nil {
+					return progress, err
+				}
+			}
+			follows = append(follows, follow)
+
+			if len(follows) >= i.config.FollowBatchSize {
+				if err := flush(line); err != nil {
+					return progress, err
+				}
+			}
+
+		default:
+			if err := reject(raw, errors.New("unknown record type "+record.Type)); err != nil {
+				return progress, err
+			}
+		}
+	}
+
+	if line > progress.Line || len(activities) > 0 || len(follows) > 0 {
+		if err := flush(line); err != nil {
+			return progress, err
+		}
+	}
+
+	return progress, nil
+}
+
+// activityFeed validates an activity record and returns the feed it belongs to
+func (i *Importer) activityFeed(record *Record) (*getstream.FlatFeed, error) {
+	if record.Activity == nil {
+		return nil, errors.New("missing activity")
+	}
+	if record.Activity.Actor == "" || record.Activity.Verb == "" || record.Activity.Object == "" {
+		return nil, errors.New("activity requires actor, verb and object")
+	}
+
+	feedSlug, userID, err := splitFeedID(record.Feed)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.client.FlatFeed(feedSlug, userID)
+}
+
+// followInput validates a follow record
+func followInput(record *Record) (getstream.PostFlatFeedFollowingManyInput, error) {
+	follow := getstream.PostFlatFeedFollowingManyInput{}
+
+	sourceSlug, sourceID, err := splitFeedID(record.Source)
+	if err != nil {
+		return follow, err
+	}
+	targetSlug, targetID, err := splitFeedID(record.Target)
+	if err != nil {
+		return follow, err
+	}
+
+	follow.Source = sourceSlug + ":" + sourceID
+	follow.Target = targetSlug + ":" + targetID
+	return follow, nil
+}
+
+// splitFeedID validates a feed id such as "user:bob" and returns its slug and user id
+func splitFeedID(feedID string) (string, string, error) {
+	parts := strings.SplitN(feedID, ":", 2)
+	if len(parts) != 2 {
+		return "", "", errors.New("invalid feed " + feedID)
+	}
+
+	feedSlug, err := getstream.ValidateFeedSlug(parts[0])
+	if err != nil {
+		return "", "", err
+	}
+	userID, err := getstream.ValidateUserID(parts[1])
+	if err != nil {
+		return "", "", err
+	}
+
+	return feedSlug, userID, nil
+}
+
+func (i *Importer) readCheckpoint() (int, error) {
+	if i.config.CheckpointPath == "" {
+		return 0, nil
+	}
+
+	payload, err := ioutil.ReadFile(i.config.CheckpointPath)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	saved := &checkpoint{}
+	err = json.Unmarshal(payload, saved)
+	if err != nil {
+		return 0, err
+	}
+	return saved.Line, nil
+}
+
+// writeCheckpoint replaces the checkpoint file, through a rename so it is never left half written
+func (i *Importer) writeCheckpoint(line int) error {
+	if i.config.CheckpointPath == "" {
+		return nil
+	}
+
+	payload, err := json.Marshal(&checkpoint{Line: line})
+	if err != nil {
+		return err
+	}
+
+	temporary := i.config.CheckpointPath + ".tmp"
+	err = ioutil.WriteFile(temporary, payload, 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(temporary, i.config.CheckpointPath)
+}
@@ -0,0 +1,189 @@
+package importer_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/importer"
+)
+
+const input = `{"type": "activity", "feed": "user:bob", "activity": {"actor": "bob", "verb": "post", "object": "post:1", "foreign_id": "post:1", "color": "blue"}}
+{"type": "activity", "feed": "user:bob", "activity": {"actor": "bob", "verb": "post", "object": "post:2", "foreign_id": "post:2"}}
+{"type": "activity", "feed": "user:bob", "activity": {"actor": "bob", "verb": "post"}}
+{"type": "activity", "feed": "user:alice", "activity": {"actor": "alice", "verb": "post", "object": "post:3"}}
+
+not json
+{"type": "follow", "source": "timeline:bob", "target": "user:alice"}
+{"type": "follow", "source": "timeline:alice", "target": "user bob"}
+{"type": "follow", "source": "timeline:alice", "target": "user:bob"}
+{"type": "comment"}
+{"type": "activity", "feed": "user:bob", "activity": {"actor": "bob", "verb": "post", "object": "post:4"}}
+`
+
+type recorder struct {
+	requests []string
+	bodies   []string
+	fail     string
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path := strings.TrimPrefix(req.URL.Path, "/api/v1.0/")
+	if path == r.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"code": 500, "exception": "InternalError"}`))
+		return
+	}
+
+	body, _ := ioutil.ReadAll(req.Body)
+	r.requests = append(r.requests, req.Method+" "+path)
+	r.bodies = append(r.bodies, string(body))
+	w.Write([]byte(`{"activities": []}`))
+}
+
+func setup(t *testing.T, handler http.Handler) (*getstream.Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, err = url.Parse(server.URL + "/api/v1.0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, server
+}
+
+func TestImport(t *testing.T) {
+	api := &recorder{}
+	client, server := setup(t, api)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var reported []importer.Progress
+	imp := importer.New(client, &importer.Config{
+		CheckpointPath: filepath.Join(dir, "checkpoint.json"),
+		RejectsPath:    filepath.Join(dir, "rejects.ndjson"),
+		OnProgress: func(progress importer.Progress) {
+			reported = append(reported, progress)
+		},
+	})
+
+	progress, err := imp.Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Activities != 4 || progress.Follows != 2 || progress.Rejected != 4 || progress.Line != 11 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+	if len(reported) != 4 {
+		t.Fatal("Expected a progress report per batch, got:", reported)
+	}
+
+	expected := []string{
+		"POST feed/user/bob/",
+		"POST feed/user/alice/",
+		"POST follow_many/",
+		"POST feed/user/bob/",
+	}
+	if strings.Join(api.requests, ",") != strings.Join(expected, ",") {
+		t.Fatal("Unexpected requests:", api.requests)
+	}
+
+	var batch struct {
+		Activities []map[string]interface{} `json:"activities"`
+	}
+	json.Unmarshal([]byte(api.bodies[0]), &batch)
+	if len(batch.Activities) != 2 || batch.Activities[0]["color"] != "blue" || batch.Activities[1]["foreign_id"] != "post:2" {
+		t.Fatal("Unexpected batch:", api.bodies[0])
+	}
+	if api.bodies[2] != `[{"source":"timeline:bob","target":"user:alice"},{"source":"timeline:alice","target":"user:bob"}]` {
+		t.Fatal("Unexpected follows:", api.bodies[2])
+	}
+
+	rejects, err := ioutil.ReadFile(filepath.Join(dir, "rejects.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(rejects)), "\n")
+	if len(lines) != 4 {
+		t.Fatal("Expected 4 rejects, got:", string(rejects))
+	}
+	reject := &importer.Reject{}
+	json.Unmarshal([]byte(lines[1]), reject)
+	if reject.Line != 6 || string(*reject.Record) != `"not json"` {
+		t.Fatal("Unexpected reject:", lines[1])
+	}
+
+	// the checkpoint is at the end, importing again does nothing
+	api.requests = nil
+	progress, err = imp.Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(api.requests) != 0 || progress.Activities != 0 {
+		t.Fatal("Expected nothing to be imported again, got:", api.requests)
+	}
+}
+
+func TestImportResume(t *testing.T) {
+	api := &recorder{fail: "follow_many/"}
+	client, server := setup(t, api)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "importer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	checkpointPath := filepath.Join(dir, "checkpoint.json")
+	imp := importer.New(client, &importer.Config{CheckpointPath: checkpointPath})
+
+	progress, err := imp.Import(strings.NewReader(input))
+	if err == nil {
+		t.Fatal("Expected the failing follow_many to stop the import")
+	}
+	if progress.Activities != 3 || progress.Line != 6 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+
+	checkpoint, err := ioutil.ReadFile(checkpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(checkpoint) != `{"line":6}` {
+		t.Fatal("Unexpected checkpoint:", string(checkpoint))
+	}
+
+	api.fail = ""
+	api.requests = nil
+	progress, err = imp.Import(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if progress.Follows != 2 || progress.Activities != 1 {
+		t.Fatal("Unexpected progress:", progress)
+	}
+	if strings.Join(api.requests, ",") != "POST follow_many/,POST feed/user/bob/" {
+		t.Fatal("Unexpected requests:", api.requests)
+	}
+}
@@ -0,0 +1,99 @@
+package getstream
+
+import (
+	"time"
+)
+
+// Tracer starts a Span for every request, implement it on top of the tracing library in use
+// (OpenTelemetry, OpenTracing, ...) to keep the Client free of vendor dependencies
+type Tracer interface {
+	StartSpan(operation string) Span
+}
+
+// Span is a single traced request
+type Span interface {
+	SetAttribute(key string, value interface{})
+	End(err error)
+}
+
+// TracingInterceptor traces every request in a Span named after its Operation, with the attributes
+// stream.feed_group, stream.feed_id, http.method, http.status_code, stream.retries and
+// stream.duration_ms (the duration reported by the API), as well as stream.region with RegionFailover
+func TracingInterceptor(tracer Tracer) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			span := tracer.StartSpan(req.Operation)
+			if req.FeedGroup != "" {
+				span.SetAttribute("stream.feed_group", req.FeedGroup)
+				span.SetAttribute("stream.feed_id", req.FeedID.Value())
+			}
+			span.SetAttribute("http.method", req.Method)
+
+			resp, err := next(req)
+
+			if resp != nil {
+				span.SetAttribute("http.status_code", resp.StatusCode)
+				span.SetAttribute("stream.retries", resp.Retries)
+				span.SetAttribute("stream.duration_ms", float64(resp.Duration)/float64(time.Millisecond))
+				if resp.Region != "" {
+					span.SetAttribute("stream.region", resp.Region)
+				}
+			}
+			span.End(err)
+
+			return resp, err
+		}
+	}
+}
+
+// RequestMetrics describes a finished request
+type RequestMetrics struct {
+	Operation  string
+	FeedGroup  string
+	StatusCode int // 0 when there was no response
+	Retries    int
+	Region     string
+	// Latency is measured by the Client, APIDuration is reported by the API
+	Latency     time.Duration
+	APIDuration time.Duration
+	Err         error
+}
+
+// Metrics records RequestMetrics, implement it on top of the metrics library in use
+type Metrics interface {
+	ObserveRequest(metrics *RequestMetrics)
+}
+
+// MetricsFunc adapts a function to Metrics
+type MetricsFunc func(metrics *RequestMetrics)
+
+// ObserveRequest calls f(metrics)
+func (f MetricsFunc) ObserveRequest(metrics *RequestMetrics) {
+	f(metrics)
+}
+
+// MetricsInterceptor records RequestMetrics for every request
+func MetricsInterceptor(metrics Metrics) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			start := time.Now()
+			resp, err := next(req)
+
+			observed := &RequestMetrics{
+				Operation: req.Operation,
+				FeedGroup: req.FeedGroup,
+				Latency:   time.Since(start),
+				Err:       err,
+			}
+			if resp != nil {
+				observed.StatusCode = resp.StatusCode
+				observed.Retries = resp.Retries
+				observed.APIDuration = resp.Duration
+				observed.Region = resp.Region
+			}
+			metrics.ObserveRequest(observed)
+
+			return resp, err
+		}
+	}
+}
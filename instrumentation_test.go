@@ -0,0 +1,104 @@
+package getstream_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+type testSpan struct {
+	name       string
+	attributes map[string]interface{}
+	err        error
+	ended      bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.attributes[key] = value
+}
+
+func (s *testSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+type testTracer struct {
+	spans []*testSpan
+}
+
+func (t *testTracer) StartSpan(operation string) getstream.Span {
+	span := &testSpan{name: operation, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return span
+}
+
+func TestTracingAndMetricsInterceptors(t *testing.T) {
+	calls := 0
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Method == "GET" && calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"code": 0, "exception": "ServiceUnavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"duration": "12.5ms", "results": [], "id": "1"}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	tracer := &testTracer{}
+	var metrics []*getstream.RequestMetrics
+	client.Interceptors = []getstream.Interceptor{
+		getstream.TracingInterceptor(tracer),
+		getstream.MetricsInterceptor(getstream.MetricsFunc(func(m *getstream.RequestMetrics) {
+			metrics = append(metrics, m)
+		})),
+		getstream.RetryInterceptor(2, time.Millisecond),
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 || len(tracer.spans) != 2 || len(metrics) != 2 {
+		t.Fatal("Expected the read to be retried once, got:", calls, tracer.spans, metrics)
+	}
+
+	span := tracer.spans[0]
+	if span.name != "flat.activities" || !span.ended || span.err != nil {
+		t.Fatal("Unexpected span:", span)
+	}
+	expected := map[string]interface{}{
+		"stream.feed_group":  "user",
+		"stream.feed_id":     "user:bob",
+		"http.method":        "GET",
+		"http.status_code":   200,
+		"stream.retries":     1,
+		"stream.duration_ms": 12.5,
+	}
+	if fmt.Sprint(span.attributes) != fmt.Sprint(expected) {
+		t.Fatal("Unexpected span attributes:", span.attributes)
+	}
+	if tracer.spans[1].name != "flat.add_activity" {
+		t.Fatal("Unexpected span:", tracer.spans[1].name)
+	}
+
+	m := metrics[0]
+	if m.Operation != "flat.activities" || m.FeedGroup != "user" || m.StatusCode != 200 || m.Retries != 1 || m.APIDuration != 12500*time.Microsecond || m.Latency <= 0 {
+		t.Fatal("Unexpected metrics:", m)
+	}
+}
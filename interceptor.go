@@ -0,0 +1,287 @@
+package getstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Request describes a request made by the Client, as seen by Interceptors
+// Interceptors run before the http request is built and authenticated, changes they make to
+// Feed, Auth, Method, Path, Params, Payload and Header are sent to the API
+type Request struct {
+	// Operation names what the request does, such as "flat.add_activity" or "client.follow_many"
+	Operation string
+	// Feed, FeedGroup and FeedID are empty for requests which aren't about a single feed
+	Feed      Feed
+	FeedGroup string
+	FeedID    FeedID
+	Auth      AuthKind
+	// Token is the JWT token of AuthJWT requests
+	Token   string
+	Method  string
+	BaseURL *url.URL
+	// Path is relative to BaseURL, Params are added to its query
+	Path   string
+	Params map[string]string
+	// Payload is the request body, it is sent again when a request is retried
+	Payload []byte
+	// Header is added to the headers set by the Client
+	Header http.Header
+	// HTTP is the last http request sent for this Request, nil until it is sent
+	HTTP *http.Request
+	// Cancel, when set, aborts the request once it is closed, such as with a context's Done channel:
+	// the http request is cancelled and RetryInterceptor stops waiting to retry
+	Cancel <-chan struct{}
+}
+
+// AuthKind is the way a Request is authenticated
+type AuthKind string
+
+const (
+	// AuthFeedSignature signs the request with the feed token
+	AuthFeedSignature AuthKind = "feed_signature"
+	// AuthFeedJWT authenticates the request with a feed scope JWT token
+	AuthFeedJWT AuthKind = "feed_jwt"
+	// AuthApp signs the request with the API key and secret
+	AuthApp AuthKind = "app"
+	// AuthJWT authenticates the request with Request.Token
+	AuthJWT AuthKind = "jwt"
+)
+
+// Response is the response to a Request
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	// Duration is the time the API reports it took to handle the request
+	Duration time.Duration
+	// Retries is the number of times the request was sent again, see RetryInterceptor
+	Retries int
+	// Cached is set when the response was read from a cache, see CacheInterceptor
+	Cached bool
+	// Coalesced is set when the response was shared with an identical request, see Coalescer
+	Coalesced bool
+	// Region is the region which served the response, see RegionFailover
+	Region string
+}
+
+// RoundTrip sends a Request, the Response is returned as well when the API returned an error
+type RoundTrip func(req *Request) (*Response, error)
+
+// Interceptor wraps the RoundTrip of every request made by a Client
+// The first of the Client Interceptors is the outermost
+type Interceptor func(next RoundTrip) RoundTrip
+
+// WithCancel returns a copy of the Client whose requests have cancel as their Request.Cancel,
+// such as a context's Done channel, the Client itself is left as it is
+func (c *Client) WithCancel(cancel <-chan struct{}) *Client {
+	copied := *c
+	copied.Interceptors = append([]Interceptor{func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			req.Cancel = cancel
+			return next(req)
+		}
+	}}, c.Interceptors...)
+	return &copied
+}
+
+// RetryInterceptor sends GET requests again, up to maxRetries times, when they fail
+// with a transport error or with a 5xx or 429 response, waiting backoff, doubled every retry
+// Closing Request.Cancel stops the wait, the last failure is returned
+func RetryInterceptor(maxRetries int, backoff time.Duration) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			resp, err := next(req)
+			if req.Method != "GET" {
+				return resp, err
+			}
+
+			wait := backoff
+			for retries := 1; retries <= maxRetries && retryable(resp, err); retries++ {
+				timer := time.NewTimer(wait)
+				select {
+				case <-req.Cancel:
+					timer.Stop()
+					return resp, err
+				case <-timer.C:
+				}
+				wait *= 2
+
+				resp, err = next(req)
+				if resp != nil {
+					resp.Retries = retries
+				}
+			}
+			return resp, err
+		}
+	}
+}
+
+// retryable reports whether a failed request is worth sending again: 5xx and 429 responses, and
+// transport errors (net.Error, which includes *url.Error); errors building or signing the request
+// would only fail again
+func retryable(resp *Response, err error) bool {
+	if err == nil || err == ErrCircuitOpen {
+		return false
+	}
+	if resp != nil {
+		return resp.StatusCode >= 500 || resp.StatusCode == 429
+	}
+	_, ok := err.(net.Error)
+	return ok
+}
+
+// HookInterceptor calls before ahead of every request and after once it is done,
+// an error returned by before fails the request without sending it
+// Either function may be nil
+func HookInterceptor(before func(req *Request) error, after func(req *Request, resp *Response, err error)) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			var resp *Response
+			var err error
+			if before != nil {
+				err = before(req)
+			}
+			if err == nil {
+				resp, err = next(req)
+			}
+			if after != nil {
+				after(req, resp, err)
+			}
+			return resp, err
+		}
+	}
+}
+
+// roundTrip is the innermost RoundTrip, which builds the http request and sends it over Client.HTTP
+func (c *Client) roundTrip(req *Request) (*Response, error) {
+	httpReq, err := c.newHTTPRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	req.HTTP = httpReq
+
+	var entry *LogEntry
+	if c.Logger != nil {
+		entry = c.logRequest(httpReq)
+	}
+	start := time.Now()
+
+	resp, err := c.send(httpReq)
+
+	if entry != nil {
+		c.logResponse(entry, start, resp, err)
+	}
+	return resp, err
+}
+
+// send performs the http request and handles the response
+func (c *Client) send(req *http.Request) (*Response, error) {
+	// perform the http request
+	httpResp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	// read the response
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &Response{
+		StatusCode: httpResp.StatusCode,
+		Header:     httpResp.Header,
+		Body:       body,
+		Duration:   apiDuration(body),
+	}
+
+	// handle the response
+	switch {
+	case resp.StatusCode/100 == 2: // SUCCESS
+		return resp, nil
+	default:
+		var respErr Error
+		err = json.Unmarshal(body, &respErr)
+		if err != nil {
+			return resp, err
+		}
+		return resp, &respErr
+	}
+}
+
+// apiDuration reads the duration the API reports in its responses, such as "12.5ms"
+func apiDuration(body []byte) time.Duration {
+	var output struct {
+		Duration string `json:"duration"`
+	}
+	if json.Unmarshal(body, &output) != nil {
+		return 0
+	}
+	duration, err := time.ParseDuration(output.Duration)
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// feedOperation names a request of the feed API after its feed type and path
+func feedOperation(f Feed, method string, path string, payload []byte, params map[string]string) string {
+	prefix := "client"
+	switch f.(type) {
+	case *FlatFeed:
+		prefix = "flat"
+	case *AggregatedFeed:
+		prefix = "aggregated"
+	case *NotificationFeed:
+		prefix = "notification"
+	case *GeneralFeed:
+		prefix = "feed"
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case path == "follow_many/":
+		return "client.follow_many"
+	case path == "activities/":
+		return "client.update_activities"
+	case path == "feed/add_to_many/":
+		return "client.add_to_many"
+	case path == "stats/follow/":
+		return "client.follow_stats"
+	case parts[0] == "user":
+		return "client." + strings.ToLower(method) + "_user"
+	case parts[0] == "enrich":
+		return prefix + ".enriched_activities"
+	case parts[0] != "feed" || len(parts) < 3:
+		return prefix + "." + strings.ToLower(method) + "_" + strings.Replace(strings.Trim(path, "/"), "/", "_", -1)
+	}
+
+	rest := parts[3:]
+	switch {
+	case len(rest) == 0 && method == "GET":
+		return prefix + ".activities"
+	case len(rest) == 0 && method == "POST" && bytes.HasPrefix(payload, []byte(`{"activities":`)):
+		return prefix + ".add_activities"
+	case len(rest) == 0 && method == "POST":
+		return prefix + ".add_activity"
+	case len(rest) >= 1 && rest[0] == "following" && method == "POST":
+		return prefix + ".follow"
+	case len(rest) >= 2 && rest[0] == "following" && method == "DELETE":
+		return prefix + ".unfollow"
+	case len(rest) >= 1 && (rest[0] == "following" || rest[0] == "followers") && method == "GET":
+		return prefix + "." + rest[0]
+	case len(rest) == 1 && method == "DELETE" && params["foreign_id"] == "1":
+		return prefix + ".remove_activity_by_foreign_id"
+	case len(rest) == 1 && method == "DELETE":
+		return prefix + ".remove_activity"
+	}
+	return prefix + "." + strings.ToLower(method)
+}
@@ -0,0 +1,224 @@
+package getstream_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the request to be answered by the interceptor")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var operations []string
+	client.Interceptors = []getstream.Interceptor{
+		func(next getstream.RoundTrip) getstream.RoundTrip {
+			return func(req *getstream.Request) (*getstream.Response, error) {
+				operations = append(operations, req.Operation)
+				return &getstream.Response{StatusCode: 200, Body: []byte(`{}`)}, nil
+			}
+		},
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := client.FlatFeed("user", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed.AddActivities([]*getstream.Activity{{Actor: "bob", Verb: "post", Object: "post:1"}})
+	feed.RemoveActivity(&getstream.Activity{ID: "1"})
+	feed.FollowFeedWithCopyLimit(alice, 10)
+	feed.Unfollow(alice)
+	feed.FollowersWithLimitAndSkip(10, 0)
+	client.FollowMany(nil, 0)
+
+	expected := []string{"flat.add_activities", "flat.remove_activity", "flat.follow", "flat.unfollow", "flat.followers", "client.follow_many"}
+	if fmt.Sprint(operations) != fmt.Sprint(expected) {
+		t.Fatal("Unexpected operations:", operations)
+	}
+}
+
+func TestInterceptorMutatesRequest(t *testing.T) {
+	var query, tenant, body string
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		tenant = r.Header.Get("X-Tenant")
+		payload, _ := ioutil.ReadAll(r.Body)
+		body = string(payload)
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var auths []getstream.AuthKind
+	client.Interceptors = []getstream.Interceptor{
+		getstream.HookInterceptor(func(req *getstream.Request) error {
+			auths = append(auths, req.Auth)
+			req.Header.Set("X-Tenant", "acme")
+			if req.Method == "GET" {
+				req.Params = map[string]string{"limit": "5"}
+			} else {
+				req.Payload = []byte(`{"actor":"acme:bob"}`)
+			}
+			return nil
+		}, nil),
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(query, "limit=5") || tenant != "acme" {
+		t.Fatal("Expected the params and header to be changed, got:", query, tenant)
+	}
+
+	err = feed.UpdateActivity(&getstream.Activity{ForeignID: "post:1", Actor: "bob", Verb: "post", Object: "post:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != `{"actor":"acme:bob"}` {
+		t.Fatal("Expected the payload to be changed, got:", body)
+	}
+
+	expected := []getstream.AuthKind{getstream.AuthFeedSignature, getstream.AuthFeedJWT}
+	if fmt.Sprint(auths) != fmt.Sprint(expected) {
+		t.Fatal("Unexpected auth kinds:", auths)
+	}
+}
+
+func TestHookInterceptorRejects(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Expected the request to be rejected")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	denied := errors.New("read only")
+	var afterErr error
+	client.Interceptors = []getstream.Interceptor{
+		getstream.HookInterceptor(func(req *getstream.Request) error {
+			if req.Method != "GET" {
+				return denied
+			}
+			return nil
+		}, func(req *getstream.Request, resp *getstream.Response, err error) {
+			afterErr = err
+		}),
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	if err != denied || afterErr != denied {
+		t.Fatal("Expected the request to be rejected, got:", err, afterErr)
+	}
+}
+
+func TestRetryInterceptorCancel(t *testing.T) {
+	requested := make(chan struct{}, 1)
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code": 0, "exception": "ServiceUnavailable"}`))
+		requested <- struct{}{}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	cancel := make(chan struct{})
+	client.Interceptors = []getstream.Interceptor{
+		getstream.HookInterceptor(func(req *getstream.Request) error {
+			req.Cancel = cancel
+			return nil
+		}, nil),
+		getstream.RetryInterceptor(3, time.Hour),
+	}
+	go func() {
+		<-requested
+		close(cancel)
+	}()
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	go func() {
+		_, err := feed.Activities(nil)
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+		if err == nil {
+			t.Fatal("Expected the last failure to be returned")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected closing Cancel to stop the retry wait")
+	}
+}
+
+func TestRetryInterceptorTransportErrors(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	attempts := 0
+	client.Interceptors = []getstream.Interceptor{
+		getstream.RetryInterceptor(2, time.Millisecond),
+		getstream.HookInterceptor(func(req *getstream.Request) error {
+			attempts++
+			return nil
+		}, nil),
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err == nil || attempts != 3 {
+		t.Fatal("Expected the request to be retried after the connection failed, got:", err, attempts)
+	}
+
+	attempts = 0
+	failure := errors.New("can't sign the request")
+	client.Interceptors[1] = getstream.HookInterceptor(func(req *getstream.Request) error {
+		attempts++
+		return failure
+	}, nil)
+	_, err = feed.Activities(nil)
+	if err != failure || attempts != 1 {
+		t.Fatal("Expected the request to fail without retries, got:", err, attempts)
+	}
+}
@@ -0,0 +1,154 @@
+package getstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Redacted replaces secrets in a LogEntry
+const Redacted = "[REDACTED]"
+
+// Logger receives a LogEntry for every request made by the Client
+// Log is called from the goroutine making the request
+type Logger interface {
+	Log(entry *LogEntry)
+}
+
+// LoggerFunc adapts a function to a Logger
+type LoggerFunc func(entry *LogEntry)
+
+// Log calls f(entry)
+func (f LoggerFunc) Log(entry *LogEntry) {
+	f(entry)
+}
+
+// LogEntry describes a request and its response, with the secrets redacted:
+// the Authorization and X-Api-Key headers, the api_key query param and the feed tokens in to fields
+type LogEntry struct {
+	Method string
+	// URL is the full URL of the request, Path only its path
+	URL      string
+	Path     string
+	Header   http.Header
+	Status   int // 0 when there was no response
+	Duration time.Duration
+	Err      error
+
+	// RequestBody and ResponseBody are only set when the Client has LogBodies set
+	RequestBody  []byte
+	ResponseBody []byte
+}
+
+// logRequest prepares a LogEntry for req, reading the request body when it is logged
+func (c *Client) logRequest(req *http.Request) *LogEntry {
+	entry := &LogEntry{
+		Method: req.Method,
+		URL:    redactURL(req.URL),
+		Path:   req.URL.Path,
+		Header: redactHeader(req.Header),
+	}
+
+	if c.LogBodies && req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err == nil && len(body) > 0 {
+			entry.RequestBody = redactBody(body)
+		}
+	}
+
+	return entry
+}
+
+// logResponse completes the LogEntry and hands it to the Logger
+func (c *Client) logResponse(entry *LogEntry, start time.Time, resp *Response, err error) {
+	entry.Duration = time.Since(start)
+	entry.Err = err
+	if resp != nil {
+		entry.Status = resp.StatusCode
+		if c.LogBodies && len(resp.Body) > 0 {
+			entry.ResponseBody = redactBody(resp.Body)
+		}
+	}
+
+	c.Logger.Log(entry)
+}
+
+func redactHeader(header http.Header) http.Header {
+	result := http.Header{}
+	for key, values := range header {
+		if strings.EqualFold(key, "Authorization") || strings.EqualFold(key, "X-Api-Key") {
+			result[key] = []string{Redacted}
+			continue
+		}
+		result[key] = append([]string{}, values...)
+	}
+	return result
+}
+
+func redactURL(u *url.URL) string {
+	redacted := *u
+	query := redacted.Query()
+	if query.Get("api_key") != "" {
+		query.Set("api_key", Redacted)
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.String()
+}
+
+// redactBody removes the feed tokens from to fields, "user:bob token" or ["user:bob", "token"]
+// bodies which aren't JSON or don't have to fields are returned as they are
+func redactBody(body []byte) []byte {
+	if !bytes.Contains(body, []byte(`"to"`)) {
+		return body
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return body
+	}
+	redactTo(value)
+
+	result, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+	return result
+}
+
+func redactTo(value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if key != "to" {
+				redactTo(field)
+				continue
+			}
+			tos, ok := field.([]interface{})
+			if !ok {
+				continue
+			}
+			for i, to := range tos {
+				switch to := to.(type) {
+				case string:
+					if index := strings.Index(to, " "); index >= 0 {
+						tos[i] = to[:index] + " " + Redacted
+					}
+				case []interface{}:
+					if len(to) == 2 {
+						to[1] = Redacted
+					}
+				}
+			}
+		}
+	case []interface{}:
+		for _, item := range value {
+			redactTo(item)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package getstream_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestClientLogger(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": "1", "actor": "bob", "verb": "post", "object": "post:1", "to": [["flat:alice", "alice_token"]]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var entries []*getstream.LogEntry
+	client.Logger = getstream.LoggerFunc(func(entry *getstream.LogEntry) {
+		entries = append(entries, entry)
+	})
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := client.FlatFeed("flat", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = feed.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1", To: []getstream.Feed{alice}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatal("Expected a single log entry, got:", entries)
+	}
+	entry := entries[0]
+	if entry.Method != "POST" || entry.Path != "/api/v1.0/feed/flat/bob/" || entry.Status != 200 || entry.Duration <= 0 {
+		t.Fatal("Unexpected entry:", entry)
+	}
+	if entry.Header.Get("Authorization") != getstream.Redacted {
+		t.Fatal("Expected the Authorization header to be redacted, got:", entry.Header.Get("Authorization"))
+	}
+	if strings.Contains(entry.URL, "my_key") || !strings.Contains(entry.URL, "api_key=%5BREDACTED%5D") {
+		t.Fatal("Expected the api key to be redacted, got:", entry.URL)
+	}
+	if entry.RequestBody != nil || entry.ResponseBody != nil {
+		t.Fatal("Expected no bodies without LogBodies")
+	}
+
+	client.LogBodies = true
+	_, err = feed.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1", To: []getstream.Feed{alice}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry = entries[1]
+	if !strings.Contains(string(entry.RequestBody), `"to":["flat:alice [REDACTED]"]`) || strings.Contains(string(entry.RequestBody), alice.Token()) {
+		t.Fatal("Expected the feed token to be redacted from the request, got:", string(entry.RequestBody))
+	}
+	if !strings.Contains(string(entry.ResponseBody), `"to":[["flat:alice","[REDACTED]"]]`) {
+		t.Fatal("Expected the feed token to be redacted from the response, got:", string(entry.ResponseBody))
+	}
+}
+
+func TestClientLoggerRedactsAPIKey(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var entries []*getstream.LogEntry
+	client.Logger = getstream.LoggerFunc(func(entry *getstream.LogEntry) {
+		entries = append(entries, entry)
+	})
+	client.LogBodies = true
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != 1 {
+		t.Fatal("Expected a single log entry, got:", entries)
+	}
+	entry := entries[0]
+	if entry.Header.Get("X-Api-Key") != getstream.Redacted {
+		t.Fatal("Expected the X-Api-Key header to be redacted, got:", entry.Header.Get("X-Api-Key"))
+	}
+	logged := fmt.Sprintf("%+v %s %s", *entry, entry.RequestBody, entry.ResponseBody)
+	if strings.Contains(logged, "my_key") {
+		t.Fatal("Expected the api key to be redacted everywhere, got:", logged)
+	}
+}
+
+func TestClientLoggerError(t *testing.T) {
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"code": 17, "exception": "NotAllowedException"}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	var entry *getstream.LogEntry
+	client.Logger = getstream.LoggerFunc(func(e *getstream.LogEntry) {
+		entry = e
+	})
+	client.LogBodies = true
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+
+	if entry == nil || entry.Status != 403 || entry.Err != err || !strings.Contains(string(entry.ResponseBody), "NotAllowedException") {
+		t.Fatal("Unexpected entry:", entry)
+	}
+}
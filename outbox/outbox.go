@@ -0,0 +1,418 @@
+// Package outbox writes to GetStream.io asynchronously. Activity adds and removals, follows
+// and unfollows are stored in an outbox Store and return at once, a Writer then delivers them
+// in the background, in order for every feed, retrying failed writes with backoff.
+// Writes which can't be delivered end up as dead letters, which can be listed and requeued.
+//
+// Deliveries are retried when the outcome of a request is unknown, give activities a ForeignID
+// and a Time so that the API ignores the activities which are added twice.
+package outbox
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+// Kind is the kind of write of an Entry
+type Kind string
+
+// Entry kinds
+const (
+	KindAddActivity               Kind = "add_activity"
+	KindRemoveActivity            Kind = "remove_activity"
+	KindRemoveActivityByForeignID Kind = "remove_activity_by_foreign_id"
+	KindFollow                    Kind = "follow"
+	KindUnfollow                  Kind = "unfollow"
+)
+
+// Status is the delivery status of an Entry
+type Status string
+
+// Entry statuses
+const (
+	StatusPending   Status = "pending"
+	StatusDelivered Status = "delivered"
+	StatusDead      Status = "dead"
+)
+
+// Entry is a write waiting in the outbox
+type Entry struct {
+	ID   int64 `json:"id"`
+	Kind Kind  `json:"kind"`
+	// Feed is the feed written to, the following feed for follows, such as "user:bob"
+	Feed     string              `json:"feed"`
+	Activity *getstream.Activity `json:"activity,omitempty"`
+	// Target is the followed feed
+	Target    string `json:"target,omitempty"`
+	CopyLimit int    `json:"copy_limit,omitempty"`
+
+	Status    Status    `json:"status"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// NextAttempt is when a failed delivery is tried again
+	NextAttempt time.Time `json:"next_attempt"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+// Config configures a Writer
+type Config struct {
+	// MaxAttempts is the number of deliveries tried before an entry is a dead letter, defaults to 5
+	MaxAttempts int
+	// Backoff is the wait after the first failed delivery, doubled after every failure up to MaxBackoff,
+	// defaults to one second and five minutes
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// PollInterval is how often the Store is checked for entries due to be sent again, defaults to one second
+	PollInterval time.Duration
+
+	// OnDone is called when an entry is delivered or becomes a dead letter
+	OnDone func(entry *Entry)
+}
+
+// Writer delivers the entries of a Store
+type Writer struct {
+	client *getstream.Client
+	store  Store
+	config Config
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+
+	startOnce sync.Once
+	closeOnce sync.Once
+
+	lock       sync.Mutex
+	started    bool
+	busy       map[string]bool
+	deliveries sync.WaitGroup
+}
+
+// New returns a Writer delivering the entries of store with the Client, call Start to begin delivering
+func New(client *getstream.Client, store Store, cfg *Config) *Writer {
+	config := Config{}
+	if cfg != nil {
+		config = *cfg
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 5
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = time.Second
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 5 * time.Minute
+	}
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+
+	return &Writer{
+		client: client,
+		store:  store,
+		config: config,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+		busy:   map[string]bool{},
+	}
+}
+
+// AddActivity queues adding the activity to the feed
+// An activity without a TimeStamp is stamped with the time it is queued at
+func (w *Writer) AddActivity(feed getstream.Feed, activity *getstream.Activity) (int64, error) {
+	copied := *activity
+	if copied.TimeStamp == nil {
+		now := time.Now().UTC()
+		copied.TimeStamp = &now
+	}
+	return w.enqueue(&Entry{Kind: KindAddActivity, Feed: feed.FeedID().Value(), Activity: &copied})
+}
+
+// RemoveActivity queues removing the activity, by ID, from the feed
+func (w *Writer) RemoveActivity(feed getstream.Feed, activity *getstream.Activity) (int64, error) {
+	return w.enqueue(&Entry{Kind: KindRemoveActivity, Feed: feed.FeedID().Value(), Activity: &getstream.Activity{ID: activity.ID}})
+}
+
+// RemoveActivityByForeignID queues removing the activity, by ForeignID, from the feed
+func (w *Writer) RemoveActivityByForeignID(feed getstream.Feed, activity *getstream.Activity) (int64, error) {
+	return w.enqueue(&Entry{Kind: KindRemoveActivityByForeignID, Feed: feed.FeedID().Value(), Activity: &getstream.Activity{ForeignID: activity.ForeignID}})
+}
+
+// Follow queues the feed following target, copying up to copyLimit activities
+func (w *Writer) Follow(feed getstream.Feed, target getstream.Feed, copyLimit int) (int64, error) {
+	return w.enqueue(&Entry{Kind: KindFollow, Feed: feed.FeedID().Value(), Target: target.FeedID().Value(), CopyLimit: copyLimit})
+}
+
+// Unfollow queues the feed unfollowing target
+func (w *Writer) Unfollow(feed getstream.Feed, target getstream.Feed) (int64, error) {
+	return w.enqueue(&Entry{Kind: KindUnfollow, Feed: feed.FeedID().Value(), Target: target.FeedID().Value()})
+}
+
+func (w *Writer) enqueue(entry *Entry) (int64, error) {
+	if _, err := w.feed(entry.Feed); err != nil {
+		return 0, err
+	}
+	if entry.Target != "" {
+		if _, err := w.feed(entry.Target); err != nil {
+			return 0, err
+		}
+	}
+
+	entry.Status = StatusPending
+	entry.CreatedAt = time.Now()
+	if err := w.store.Append(entry); err != nil {
+		return 0, err
+	}
+	w.notify()
+	return entry.ID, nil
+}
+
+// Status returns the entry with its delivery status
+func (w *Writer) Status(id int64) (*Entry, error) {
+	return w.store.Get(id)
+}
+
+// DeadLetters returns the entries which could not be delivered
+func (w *Writer) DeadLetters() ([]*Entry, error) {
+	return w.store.List(StatusDead)
+}
+
+// Requeue delivers a dead letter again, with its attempts reset
+func (w *Writer) Requeue(id int64) error {
+	entry, err := w.store.Get(id)
+	if err != nil {
+		return err
+	}
+	if entry.Status != StatusDead {
+		return errors.New("Only dead letters can be requeued")
+	}
+
+	entry.Status = StatusPending
+	entry.Attempts = 0
+	entry.NextAttempt = time.Time{}
+	if err = w.store.Update(entry); err != nil {
+		return err
+	}
+	w.notify()
+	return nil
+}
+
+// Start delivers entries in the background until Close is called, calling it again has no effect
+func (w *Writer) Start() {
+	w.startOnce.Do(func() {
+		w.lock.Lock()
+		defer w.lock.Unlock()
+
+		select {
+		case <-w.stop:
+			// closed before being started
+			return
+		default:
+		}
+		w.started = true
+		go w.run()
+	})
+}
+
+// Close stops delivering entries, waiting for the deliveries in progress
+// It may be called without Start and more than once
+func (w *Writer) Close() {
+	w.closeOnce.Do(func() {
+		w.lock.Lock()
+		close(w.stop)
+		started := w.started
+		w.lock.Unlock()
+
+		if started {
+			<-w.done
+		}
+		w.deliveries.Wait()
+	})
+}
+
+// Flush waits up to timeout for the pending entries to be delivered or become dead letters
+func (w *Writer) Flush(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		pending, err := w.store.List(StatusPending)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return errors.New("Timed out with entries pending")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (w *Writer) notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.dispatch()
+
+		select {
+		case <-w.stop:
+			return
+		case <-w.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatch starts delivering the pending entries of every feed which isn't being delivered to already
+func (w *Writer) dispatch() {
+	pending, err := w.store.List(StatusPending)
+	if err != nil {
+		// tried again on the next poll
+		return
+	}
+
+	var feeds []string
+	entries := map[string][]*Entry{}
+	for _, entry := range pending {
+		if entries[entry.Feed] == nil {
+			feeds = append(feeds, entry.Feed)
+		}
+		entries[entry.Feed] = append(entries[entry.Feed], entry)
+	}
+
+	now := time.Now()
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	for _, feed := range feeds {
+		if w.busy[feed] || entries[feed][0].NextAttempt.After(now) {
+			continue
+		}
+		w.busy[feed] = true
+		w.deliveries.Add(1)
+		go w.deliverFeed(feed, entries[feed])
+	}
+}
+
+// deliverFeed delivers the entries of a feed in order, up to the first failure
+func (w *Writer) deliverFeed(feed string, entries []*Entry) {
+	defer func() {
+		w.lock.Lock()
+		delete(w.busy, feed)
+		w.lock.Unlock()
+		w.deliveries.Done()
+		w.notify()
+	}()
+
+	for _, entry := range entries {
+		select {
+		case <-w.stop:
+			return
+		default:
+		}
+
+		err := w.deliver(entry)
+
+		now := time.Now()
+		entry.Attempts++
+		switch {
+		case err == nil:
+			entry.Status = StatusDelivered
+			entry.DeliveredAt = now
+			entry.LastError = ""
+		case permanent(err) || entry.Attempts >= w.config.MaxAttempts:
+			entry.Status = StatusDead
+			entry.LastError = err.Error()
+		default:
+			entry.NextAttempt = now.Add(w.backoff(entry.Attempts))
+			entry.LastError = err.Error()
+		}
+
+		if w.store.Update(entry) != nil {
+			// the entry is still pending in the store and is delivered again
+			return
+		}
+		if entry.Status == StatusPending {
+			// later entries wait for this one to keep the order
+			return
+		}
+		if w.config.OnDone != nil {
+			w.config.OnDone(entry)
+		}
+	}
+}
+
+func (w *Writer) backoff(attempts int) time.Duration {
+	wait := w.config.Backoff
+	for i := 1; i < attempts && wait < w.config.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > w.config.MaxBackoff {
+		wait = w.config.MaxBackoff
+	}
+	return wait
+}
+
+func (w *Writer) deliver(entry *Entry) error {
+	feed, err := w.feed(entry.Feed)
+	if err != nil {
+		return err
+	}
+
+	switch entry.Kind {
+	case KindAddActivity:
+		// AddActivity changes the activity, which is shared with the Store
+		activity := *entry.Activity
+		_, err = feed.AddActivity(&activity)
+		return err
+	case KindRemoveActivity:
+		return feed.RemoveActivity(entry.Activity)
+	case KindRemoveActivityByForeignID:
+		return feed.RemoveActivityByForeignID(entry.Activity)
+	}
+
+	target, err := w.feed(entry.Target)
+	if err != nil {
+		return err
+	}
+	switch entry.Kind {
+	case KindFollow:
+		return feed.FollowFeedWithCopyLimit(target, entry.CopyLimit)
+	case KindUnfollow:
+		return feed.Unfollow(target)
+	}
+	return errors.New("Unknown outbox entry kind " + string(entry.Kind))
+}
+
+// feed returns the FlatFeed of a feed id, flat feeds can write to feeds of any type
+func (w *Writer) feed(feedID string) (*getstream.FlatFeed, error) {
+	parts := strings.Split(feedID, ":")
+	if len(parts) != 2 {
+		return nil, errors.New("Invalid feed id " + feedID)
+	}
+	return w.client.FlatFeed(parts[0], parts[1])
+}
+
+// permanent tells whether sending the entry again can't succeed, the API rejected it with a 4xx status
+func permanent(err error) bool {
+	apiErr, ok := err.(*getstream.Error)
+	if !ok {
+		return false
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && apiErr.StatusCode != 429
+}
@@ -0,0 +1,290 @@
+package outbox_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/outbox"
+)
+
+type recorder struct {
+	sync.Mutex
+	requests []string
+	// failures answers the requests of a path with the error statuses, until they run out
+	failures map[string][]int
+}
+
+func (r *recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.Lock()
+	defer r.Unlock()
+
+	path := strings.TrimPrefix(req.URL.Path, "/api/v1.0/")
+	body, _ := ioutil.ReadAll(req.Body)
+	r.requests = append(r.requests, req.Method+" "+path+" "+string(body))
+
+	if statuses := r.failures[path]; len(statuses) > 0 {
+		r.failures[path] = statuses[1:]
+		w.WriteHeader(statuses[0])
+		w.Write([]byte(`{"code": 4, "exception": "InputException", "status_code": ` + strconv.Itoa(statuses[0]) + `}`))
+		return
+	}
+	w.Write([]byte(`{}`))
+}
+
+func (r *recorder) paths() []string {
+	r.Lock()
+	defer r.Unlock()
+
+	var paths []string
+	for _, request := range r.requests {
+		paths = append(paths, strings.Join(strings.SplitN(request, " ", 3)[:2], " "))
+	}
+	return paths
+}
+
+func setup(t *testing.T, handler http.Handler) (*getstream.Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL, err = url.Parse(server.URL + "/api/v1.0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client, server
+}
+
+func TestWriterDeliversInOrder(t *testing.T) {
+	api := &recorder{failures: map[string][]int{"feed/user/bob/": {503}}}
+	client, server := setup(t, api)
+	defer server.Close()
+
+	var lock sync.Mutex
+	var done []int64
+	writer := outbox.New(client, outbox.NewMemoryStore(), &outbox.Config{
+		Backoff:      time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+		OnDone: func(entry *outbox.Entry) {
+			lock.Lock()
+			done = append(done, entry.ID)
+			lock.Unlock()
+		},
+	})
+
+	bob, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeline, err := client.FlatFeed("timeline", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := writer.AddActivity(bob, &getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := writer.RemoveActivity(bob, &getstream.Activity{ID: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	follow, err := writer.Follow(timeline, bob, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = writer.AddActivity(&getstream.GeneralFeed{FeedSlug: "user", UserID: "bad id"}, &getstream.Activity{}); err == nil {
+		t.Fatal("Expected an invalid feed to be refused")
+	}
+
+	entry, err := writer.Status(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != outbox.StatusPending {
+		t.Fatal("Expected the entry to wait for Start, got:", entry.Status)
+	}
+
+	writer.Start()
+	err = writer.Flush(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.Close()
+
+	var bobPaths []string
+	for _, path := range api.paths() {
+		if strings.Contains(path, "feed/user/bob/") {
+			bobPaths = append(bobPaths, path)
+		}
+	}
+	expected := []string{"POST feed/user/bob/", "POST feed/user/bob/", "DELETE feed/user/bob/123/"}
+	if strings.Join(bobPaths, ",") != strings.Join(expected, ",") {
+		t.Fatal("Expected the removal to wait for the retried add, got:", bobPaths)
+	}
+
+	for _, id := range []int64{first, second, follow} {
+		entry, err := writer.Status(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Status != outbox.StatusDelivered || entry.DeliveredAt.IsZero() {
+			t.Fatal("Expected the entry to be delivered, got:", entry)
+		}
+	}
+	entry, _ = writer.Status(first)
+	if entry.Attempts != 2 || entry.LastError != "" {
+		t.Fatal("Expected the first add to be retried, got:", entry)
+	}
+	if len(done) != 3 {
+		t.Fatal("Expected OnDone for every entry, got:", done)
+	}
+}
+
+func TestWriterDeadLetters(t *testing.T) {
+	api := &recorder{failures: map[string][]int{
+		"feed/user/bob/":   {403},
+		"feed/user/alice/": {500, 500},
+	}}
+	client, server := setup(t, api)
+	defer server.Close()
+
+	writer := outbox.New(client, outbox.NewMemoryStore(), &outbox.Config{
+		MaxAttempts:  2,
+		Backoff:      time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+	writer.Start()
+	defer writer.Close()
+
+	bob, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := client.FlatFeed("user", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	denied, err := writer.AddActivity(bob, &getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failing, err := writer.AddActivity(alice, &getstream.Activity{Actor: "alice", Verb: "post", Object: "post:2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Flush(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dead, err := writer.DeadLetters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dead) != 2 || dead[0].ID != denied || dead[1].ID != failing {
+		t.Fatal("Unexpected dead letters:", dead)
+	}
+	if dead[0].Attempts != 1 || dead[1].Attempts != 2 || dead[0].LastError == "" {
+		t.Fatal("Expected the denied add not to be retried, got:", dead[0], dead[1])
+	}
+
+	err = writer.Requeue(failing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = writer.Flush(5 * time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := writer.Status(failing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != outbox.StatusDelivered {
+		t.Fatal("Expected the requeued entry to be delivered, got:", entry)
+	}
+	if writer.Requeue(failing) == nil {
+		t.Fatal("Expected delivered entries not to be requeued")
+	}
+}
+
+func TestWriterClose(t *testing.T) {
+	client, server := setup(t, &recorder{})
+	defer server.Close()
+
+	unstarted := outbox.New(client, outbox.NewMemoryStore(), nil)
+	closed := make(chan struct{})
+	go func() {
+		unstarted.Close()
+		unstarted.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected Close to return without Start")
+	}
+
+	writer := outbox.New(client, outbox.NewMemoryStore(), nil)
+	writer.Start()
+	writer.Start()
+	writer.Close()
+	writer.Close()
+}
+
+func TestWriterStampsActivities(t *testing.T) {
+	client, server := setup(t, &recorder{})
+	defer server.Close()
+
+	writer := outbox.New(client, outbox.NewMemoryStore(), nil)
+	defer writer.Close()
+
+	bob, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	activity := &getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"}
+	id, err := writer.AddActivity(bob, activity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activity.TimeStamp != nil {
+		t.Fatal("Expected the activity given to be left as it is")
+	}
+
+	entry, err := writer.Status(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Activity.TimeStamp == nil || entry.Activity.TimeStamp.After(time.Now()) || entry.Activity.TimeStamp.Location() != time.UTC {
+		t.Fatal("Expected the activity to be stamped in UTC when queued, got:", entry.Activity.TimeStamp)
+	}
+	first, err := entry.Activity.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	second, err := entry.Activity.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Fatal("Expected the activity time to stay the same, got:", string(first), string(second))
+	}
+}
@@ -0,0 +1,274 @@
+package outbox
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ErrNotFound is returned for unknown entry ids
+var ErrNotFound = errors.New("Outbox entry not found")
+
+// Store persists the entries of a Writer, it must be safe for concurrent use
+type Store interface {
+	// Append stores a new entry, setting its ID, ids increase with every entry
+	Append(entry *Entry) error
+	// Update replaces the stored entry with the same ID
+	Update(entry *Entry) error
+	Get(id int64) (*Entry, error)
+	// List returns the entries with the status, ordered by ID
+	List(status Status) ([]*Entry, error)
+}
+
+// MemoryStore keeps the entries in memory, they are lost when the process exits
+type MemoryStore struct {
+	lock    sync.Mutex
+	lastID  int64
+	entries map[int64]*Entry
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[int64]*Entry{},
+	}
+}
+
+// Append stores a new entry, setting its ID
+func (s *MemoryStore) Append(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.lastID++
+	entry.ID = s.lastID
+	s.put(entry)
+	return nil
+}
+
+// Update replaces the stored entry with the same ID
+func (s *MemoryStore) Update(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.entries[entry.ID]; !ok {
+		return ErrNotFound
+	}
+	s.put(entry)
+	return nil
+}
+
+// Get returns a copy of the entry
+func (s *MemoryStore) Get(id int64) (*Entry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	entry, ok := s.entries[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	copied := *entry
+	return &copied, nil
+}
+
+// List returns copies of the entries with the status, ordered by ID
+func (s *MemoryStore) List(status Status) ([]*Entry, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var result []*Entry
+	for _, entry := range s.entries {
+		if entry.Status == status {
+			copied := *entry
+			result = append(result, &copied)
+		}
+	}
+	sort.Sort(byID(result))
+	return result, nil
+}
+
+func (s *MemoryStore) put(entry *Entry) {
+	copied := *entry
+	s.entries[entry.ID] = &copied
+	if entry.ID > s.lastID {
+		s.lastID = entry.ID
+	}
+}
+
+type byID []*Entry
+
+func (a byID) Len() int           { return len(a) }
+func (a byID) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
+func (a byID) Less(i, j int) bool { return a[i].ID < a[j].ID }
+
+// FileStore keeps the entries in memory and appends every change to a file as a line of JSON,
+// synced before Append and Update return, so that undelivered entries survive restarts
+// Delivered entries are dropped from the file when it is opened again
+type FileStore struct {
+	memory *MemoryStore
+
+	lock sync.Mutex
+	file *os.File
+}
+
+// OpenFileStore opens or creates the FileStore at path
+func OpenFileStore(path string) (*FileStore, error) {
+	memory := NewMemoryStore()
+
+	file, err := os.Open(path)
+	if err == nil {
+		err = replay(file, memory)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	// rewrite the file with the entries which still matter
+	tmp := path + ".tmp"
+	file, err = os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(file)
+	for id := int64(1); id <= memory.lastID; id++ {
+		entry, ok := memory.entries[id]
+		if !ok {
+			continue
+		}
+		if entry.Status == StatusDelivered && id != memory.lastID {
+			// the last entry is kept to carry on the ids
+			delete(memory.entries, id)
+			continue
+		}
+		if err = encoder.Encode(entry); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if err = file.Sync(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	file.Close()
+	if err = os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	file, err = os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileStore{
+		memory: memory,
+		file:   file,
+	}, nil
+}
+
+// replay loads the lines of the file into memory, the last line is skipped when it was cut short
+func replay(file io.Reader, memory *MemoryStore) error {
+	reader := bufio.NewReader(file)
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			entry := &Entry{}
+			if err := json.Unmarshal(line, entry); err != nil {
+				if readErr == io.EOF {
+					return nil
+				}
+				return err
+			}
+			memory.put(entry)
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// Append stores a new entry, setting its ID
+// The entry is only kept in memory once it is written to the file, so a failed Append leaves no trace
+func (s *FileStore) Append(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// the memory is only changed through the FileStore, whose lock is held
+	s.memory.lock.Lock()
+	id := s.memory.lastID + 1
+	s.memory.lock.Unlock()
+
+	entry.ID = id
+	if err := s.write(entry); err != nil {
+		entry.ID = 0
+		return err
+	}
+
+	s.memory.lock.Lock()
+	s.memory.put(entry)
+	s.memory.lock.Unlock()
+	return nil
+}
+
+// Update replaces the stored entry with the same ID
+// The entry is only changed in memory once it is written to the file
+func (s *FileStore) Update(entry *Entry) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := s.memory.Get(entry.ID); err != nil {
+		return err
+	}
+	if err := s.write(entry); err != nil {
+		return err
+	}
+	return s.memory.Update(entry)
+}
+
+// Get returns a copy of the entry
+func (s *FileStore) Get(id int64) (*Entry, error) {
+	return s.memory.Get(id)
+}
+
+// List returns copies of the entries with the status, ordered by ID
+func (s *FileStore) List(status Status) ([]*Entry, error) {
+	return s.memory.List(status)
+}
+
+// Close closes the file
+func (s *FileStore) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.file.Close()
+}
+
+// write appends the entry to the file and syncs it, a line cut short by an error is truncated
+// so that the next line starts cleanly
+func (s *FileStore) write(entry *Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = s.file.Write(append(line, '\n'))
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		s.file.Truncate(info.Size())
+		return err
+	}
+	return nil
+}
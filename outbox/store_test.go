@@ -0,0 +1,113 @@
+package outbox_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/GetStream/stream-go/outbox"
+)
+
+func TestFileStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outbox.ndjson")
+
+	store, err := outbox.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	delivered := &outbox.Entry{Kind: outbox.KindAddActivity, Feed: "user:bob", Activity: &getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"}, Status: outbox.StatusPending}
+	pending := &outbox.Entry{Kind: outbox.KindFollow, Feed: "timeline:bob", Target: "user:alice", Status: outbox.StatusPending}
+	for _, entry := range []*outbox.Entry{delivered, pending} {
+		if err = store.Append(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	delivered.Status = outbox.StatusDelivered
+	if err = store.Update(delivered); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// a write cut short by a crash
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write([]byte(`{"id": 3, "kind": "add_act`))
+	file.Close()
+
+	store, err = outbox.OpenFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	entries, err := store.List(outbox.StatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != pending.ID || entries[0].Target != "user:alice" {
+		t.Fatal("Expected the pending entry to be kept, got:", entries)
+	}
+	if _, err = store.Get(delivered.ID); err != outbox.ErrNotFound {
+		t.Fatal("Expected the delivered entry to be dropped, got:", err)
+	}
+
+	entry := &outbox.Entry{Kind: outbox.KindUnfollow, Feed: "timeline:bob", Target: "user:alice", Status: outbox.StatusPending}
+	if err = store.Append(entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.ID != 3 {
+		t.Fatal("Expected the ids to carry on, got:", entry.ID)
+	}
+}
+
+func TestFileStoreWriteFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "outbox")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := outbox.OpenFileStore(filepath.Join(dir, "outbox.ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending := &outbox.Entry{Kind: outbox.KindFollow, Feed: "timeline:bob", Target: "user:alice", Status: outbox.StatusPending}
+	if err = store.Append(pending); err != nil {
+		t.Fatal(err)
+	}
+	// writes fail once the file is closed
+	store.Close()
+
+	failed := &outbox.Entry{Kind: outbox.KindUnfollow, Feed: "timeline:bob", Target: "user:carol", Status: outbox.StatusPending}
+	if err = store.Append(failed); err == nil {
+		t.Fatal("Expected Append to fail")
+	}
+	entries, err := store.List(outbox.StatusPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].ID != pending.ID {
+		t.Fatal("Expected the failed entry not to be kept, got:", entries)
+	}
+
+	pending.Status = outbox.StatusDelivered
+	if err = store.Update(pending); err == nil {
+		t.Fatal("Expected Update to fail")
+	}
+	entry, err := store.Get(pending.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Status != outbox.StatusPending {
+		t.Fatal("Expected the entry to stay pending, got:", entry.Status)
+	}
+}
@@ -0,0 +1,166 @@
+package getstream
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Personalization is used to call the personalization endpoints of GetStream.io
+// Resources are the endpoint names configured for your application, e.g. "follow_recommendations"
+type Personalization struct {
+	Client *Client
+}
+
+// PersonalizationResponse is the response from a personalization resource
+// Results are kept raw since their shape depends on the resource
+type PersonalizationResponse struct {
+	Duration string             `json:"duration"`
+	Next     string             `json:"next"`
+	Limit    int                `json:"limit"`
+	Offset   int                `json:"offset"`
+	Version  string             `json:"version"`
+	Results  []*json.RawMessage `json:"results"`
+}
+
+// FollowRecommendation is a Feed suggested to follow by the follow_recommendations resource
+type FollowRecommendation struct {
+	ForeignID string  `json:"foreign_id"`
+	FeedID    FeedID  `json:"feed_id"`
+	Score     float64 `json:"score"`
+}
+
+// GetPersonalizedFeedOutput is the response from the personalized_feed resource
+type GetPersonalizedFeedOutput struct {
+	Duration   string      `json:"duration"`
+	Next       string      `json:"next"`
+	Limit      int         `json:"limit"`
+	Offset     int         `json:"offset"`
+	Version    string      `json:"version"`
+	Activities []*Activity `json:"results"`
+}
+
+// Personalization returns a handle to the personalization endpoints
+func (c *Client) Personalization() *Personalization {
+	return &Personalization{
+		Client: c,
+	}
+}
+
+// Get reads a personalization resource
+func (p *Personalization) Get(resource string, params map[string]string) (*PersonalizationResponse, error) {
+	result, err := p.request("GET", resource, nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &PersonalizationResponse{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, err
+}
+
+// Post sends data to a personalization resource
+func (p *Personalization) Post(resource string, params map[string]string, data interface{}) (*PersonalizationResponse, error) {
+	payload, err := json.Marshal(map[string]interface{}{
+		"data": data,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := p.request("POST", resource, payload, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &PersonalizationResponse{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, err
+}
+
+// Delete removes data from a personalization resource
+func (p *Personalization) Delete(resource string, params map[string]string) error {
+	_, err := p.request("DELETE", resource, nil, params)
+	return err
+}
+
+// FollowRecommendations returns the Feeds recommended for a user to follow
+func (p *Personalization) FollowRecommendations(userID string, limit int) ([]*FollowRecommendation, error) {
+	params := map[string]string{
+		"user_id": userID,
+	}
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+
+	result, err := p.request("GET", "follow_recommendations", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &struct {
+		Results []*FollowRecommendation `json:"results"`
+	}{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.Results, err
+}
+
+// PersonalizedFeed returns the Activities of a feed group personalized for a user
+func (p *Personalization) PersonalizedFeed(userID string, feedSlug string, limit int, offset int) (*GetPersonalizedFeedOutput, error) {
+	feedSlug, err := ValidateFeedSlug(feedSlug)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]string{
+		"user_id":   userID,
+		"feed_slug": feedSlug,
+	}
+	if limit > 0 {
+		params["limit"] = strconv.Itoa(limit)
+	}
+	if offset > 0 {
+		params["offset"] = strconv.Itoa(offset)
+	}
+
+	result, err := p.request("GET", "personalized_feed", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetPersonalizedFeedOutput{}
+	err = json.Unmarshal(result, output)
+	if err != nil {
+		return nil, err
+	}
+
+	return output, err
+}
+
+// request uses the application level token, personalization is not scoped to a Feed
+func (p *Personalization) request(method string, resource string, payload []byte, params map[string]string) ([]byte, error) {
+	resource = strings.Trim(resource, "/")
+	if resource == "" {
+		return nil, errors.New("invalid personalization resource")
+	}
+
+	token, err := p.Client.Signer.GenerateUserScopeToken(ScopeContextPersonalization, ScopeActionAll, "*")
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Client.jwtRequest(p.Client.PersonalizationURL, method, resource+"/", payload, params, token)
+}
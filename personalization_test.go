@@ -0,0 +1,154 @@
+package getstream_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func tokenClaims(token string) map[string]interface{} {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil
+	}
+	claims := map[string]interface{}{}
+	json.Unmarshal(payload, &claims)
+	return claims
+}
+
+func TestPersonalizationFollowRecommendations(t *testing.T) {
+	var request *http.Request
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		w.Write([]byte(`{"duration": "10ms", "results": [{"foreign_id": "user:alice", "feed_id": "user:alice", "score": 0.9}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	recommendations, err := client.Personalization().FollowRecommendations("bob", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(recommendations) != 1 || recommendations[0].FeedID != "user:alice" || recommendations[0].Score != 0.9 {
+		t.Fatal("Unexpected recommendations:", recommendations)
+	}
+
+	if request.Method != "GET" || request.URL.Path != "/personalization/v1.0/follow_recommendations/" {
+		t.Fatal("Unexpected request:", request.Method, request.URL.Path)
+	}
+	query := request.URL.Query()
+	if query.Get("user_id") != "bob" || query.Get("limit") != "5" || query.Get("api_key") != "my_key" {
+		t.Fatal("Unexpected query:", query)
+	}
+	if request.Header.Get("stream-auth-type") != "jwt" {
+		t.Fatal("Expected jwt auth, got:", request.Header.Get("stream-auth-type"))
+	}
+	claims := tokenClaims(request.Header.Get("Authorization"))
+	if claims["resource"] != "personalization" || claims["action"] != "*" || claims["user_id"] != "*" {
+		t.Fatal("Expected an application level personalization token, got:", claims)
+	}
+}
+
+func TestPersonalizationPersonalizedFeed(t *testing.T) {
+	var query url.Values
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		w.Write([]byte(`{"limit": 10, "offset": 20, "results": [{"id": "1", "actor": "alice", "verb": "post", "object": "post:1", "score": 1.5}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	output, err := client.Personalization().PersonalizedFeed("bob", "timeline", 10, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if query.Get("feed_slug") != "timeline" || query.Get("offset") != "20" {
+		t.Fatal("Unexpected query:", query)
+	}
+	if len(output.Activities) != 1 || output.Activities[0].Score != 1.5 || output.Offset != 20 {
+		t.Fatal("Unexpected output:", output)
+	}
+}
+
+func TestPersonalizationGenericRequests(t *testing.T) {
+	var methods []string
+	var body []byte
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		if r.Method == "POST" {
+			body, _ = ioutil.ReadAll(r.Body)
+		}
+		if r.URL.Path == "/personalization/v1.0/missing/" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code": 404, "exception": "NotFound", "detail": "resource not found"}`))
+			return
+		}
+		w.Write([]byte(`{"results": [{"name": "x"}]}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	personalization := client.Personalization()
+
+	response, err := personalization.Get("/user_interests/", map[string]string{"user_id": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Results) != 1 {
+		t.Fatal("Expected one result, got:", response.Results)
+	}
+
+	_, err = personalization.Post("user_interests", nil, map[string]string{"user_id": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != `{"data":{"user_id":"bob"}}` {
+		t.Fatal("Unexpected body:", string(body))
+	}
+
+	err = personalization.Delete("user_interests", map[string]string{"user_id": "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = personalization.Get("missing", nil)
+	if _, ok := err.(*getstream.Error); !ok {
+		t.Fatal("Expected a getstream.Error, got:", err)
+	}
+
+	_, err = personalization.Get("", nil)
+	if err == nil {
+		t.Fatal("Expected an error for an empty resource")
+	}
+
+	expected := []string{
+		"GET /personalization/v1.0/user_interests/",
+		"POST /personalization/v1.0/user_interests/",
+		"DELETE /personalization/v1.0/user_interests/",
+		"GET /personalization/v1.0/missing/",
+	}
+	if strings.Join(methods, ",") != strings.Join(expected, ",") {
+		t.Fatal("Unexpected requests:", methods)
+	}
+}
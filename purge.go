@@ -0,0 +1,278 @@
+// +build go1.7
+
+package getstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// PurgeUserOptions configures PurgeUserWithOptions
+type PurgeUserOptions struct {
+	// ByForeignID removes Activities by ForeignID instead of by ID
+	// Activities without ForeignID are always removed by ID
+	ByForeignID bool
+	// DeleteUser deletes the Stream user as well, once the feeds are purged
+	DeleteUser bool
+	// PageSize is the number of Activities or follows read per request, defaults to 100
+	PageSize int
+}
+
+// PurgedActivity is an Activity removed by a purge
+type PurgedActivity struct {
+	Feed      FeedID `json:"feed"`
+	ID        string `json:"id"`
+	ForeignID string `json:"foreign_id,omitempty"`
+}
+
+// PurgedFollow is a follow relationship removed by a purge, Source followed Target
+type PurgedFollow struct {
+	Source FeedID `json:"source"`
+	Target FeedID `json:"target"`
+}
+
+// PurgeReport is the audit trail of a purge, listing everything it removed
+// When a purge fails the report holds what was removed before the error
+type PurgeReport struct {
+	UserID      string           `json:"user_id"`
+	Feeds       []FeedID         `json:"feeds"`
+	Activities  []PurgedActivity `json:"activities"`
+	Follows     []PurgedFollow   `json:"follows"`
+	UserDeleted bool             `json:"user_deleted"`
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  time.Time        `json:"finished_at"`
+}
+
+// purgeResult is an Activity, or a group of Activities from an aggregated or notification feed
+type purgeResult struct {
+	ID         string         `json:"id"`
+	ForeignID  string         `json:"foreign_id"`
+	Activities []*purgeResult `json:"activities"`
+}
+
+type purgeResults struct {
+	Results []*purgeResult `json:"results"`
+}
+
+// PurgeUser removes the data of a user: the Activities of the feeds feedSlug:userID for each of
+// the feedSlugs, and every follow relationship of those feeds, in both directions
+func (c *Client) PurgeUser(ctx context.Context, userID string, feedSlugs []string) (*PurgeReport, error) {
+	return c.PurgeUserWithOptions(ctx, userID, feedSlugs, nil)
+}
+
+// PurgeUserWithOptions is PurgeUser with control over how Activities are removed and
+// whether the Stream user is deleted as well
+// Activities are removed before the follow relationships, so the removal reaches the feeds
+// of the followers. The purge stops at the first error or when ctx is done, cancelling the
+// request in flight
+func (c *Client) PurgeUserWithOptions(ctx context.Context, userID string, feedSlugs []string, options *PurgeUserOptions) (*PurgeReport, error) {
+	report, err := c.WithCancel(ctx.Done()).purgeUser(ctx, userID, feedSlugs, options)
+	if err != nil && ctx.Err() != nil {
+		// the cancelled request fails with a transport error, ctx tells why
+		err = ctx.Err()
+	}
+	return report, err
+}
+
+func (c *Client) purgeUser(ctx context.Context, userID string, feedSlugs []string, options *PurgeUserOptions) (*PurgeReport, error) {
+	report := &PurgeReport{
+		UserID:    userID,
+		StartedAt: time.Now(),
+	}
+	defer func() {
+		report.FinishedAt = time.Now()
+	}()
+
+	opts := PurgeUserOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if opts.PageSize <= 0 {
+		opts.PageSize = 100
+	}
+
+	if len(feedSlugs) == 0 {
+		return report, errors.New("no feed slugs to purge")
+	}
+
+	var feeds []*FlatFeed
+	for _, feedSlug := range feedSlugs {
+		// the endpoints used are the same for every type of feed
+		feed, err := c.FlatFeed(feedSlug, userID)
+		if err != nil {
+			return report, err
+		}
+		feeds = append(feeds, feed)
+		report.Feeds = append(report.Feeds, feed.FeedID())
+	}
+
+	for _, feed := range feeds {
+		err := c.purgeActivities(ctx, feed, &opts, report)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	for _, feed := range feeds {
+		err := c.purgeFollows(ctx, feed, &opts, report)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if opts.DeleteUser {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		userID, err := ValidateUserID(userID)
+		if err != nil {
+			return report, err
+		}
+		token, err := c.Signer.GenerateUserScopeToken(ScopeContextUsers, ScopeActionDelete, userID)
+		if err != nil {
+			return report, err
+		}
+		_, err = c.jwtRequest(c.BaseURL, "DELETE", "user/"+userID+"/", nil, nil, token)
+		if err != nil {
+			return report, err
+		}
+		report.UserDeleted = true
+	}
+
+	return report, nil
+}
+
+// purgeActivities removes the Activities of a feed, reading the first page until it is empty
+func (c *Client) purgeActivities(ctx context.Context, feed *FlatFeed, opts *PurgeUserOptions, report *PurgeReport) error {
+	endpoint := "feed/" + feed.FeedSlug + "/" + feed.UserID + "/"
+	removed := make(map[string]bool)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		resultBytes, err := c.get(feed, endpoint, nil, map[string]string{
+			"limit": strconv.Itoa(opts.PageSize),
+		})
+		if err != nil {
+			return err
+		}
+
+		output := &purgeResults{}
+		err = json.Unmarshal(resultBytes, output)
+		if err != nil {
+			return err
+		}
+
+		// groups are flattened into their Activities
+		var activities []*purgeResult
+		for _, result := range output.Results {
+			if result.Activities != nil {
+				activities = append(activities, result.Activities...)
+			} else {
+				activities = append(activities, result)
+			}
+		}
+		if len(activities) == 0 {
+			return nil
+		}
+
+		for _, activity := range activities {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if removed[activity.ID] {
+				return errors.New("activity " + activity.ID + " was not removed from " + feed.FeedID().Value())
+			}
+
+			if opts.ByForeignID && activity.ForeignID != "" {
+				err = c.del(feed, endpoint+activity.ForeignID+"/", nil, map[string]string{
+					"foreign_id": "1",
+				})
+			} else {
+				err = c.del(feed, endpoint+activity.ID+"/", nil, nil)
+			}
+			if err != nil {
+				return err
+			}
+
+			removed[activity.ID] = true
+			report.Activities = append(report.Activities, PurgedActivity{
+				Feed:      feed.FeedID(),
+				ID:        activity.ID,
+				ForeignID: activity.ForeignID,
+			})
+		}
+	}
+}
+
+// purgeFollows removes the follow relationships of a feed, in both directions
+// all of them are listed before any is removed, as removing shifts the pages
+func (c *Client) purgeFollows(ctx context.Context, feed *FlatFeed, opts *PurgeUserOptions, report *PurgeReport) error {
+	var following []*GeneralFeed
+	var followers []*GeneralFeed
+
+	for skip := 0; ; skip += opts.PageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := feed.FollowingWithLimitAndSkip(opts.PageSize, skip)
+		if err != nil {
+			return err
+		}
+		following = append(following, page...)
+		if len(page) < opts.PageSize {
+			break
+		}
+	}
+
+	for skip := 0; ; skip += opts.PageSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := feed.FollowersWithLimitAndSkip(opts.PageSize, skip)
+		if err != nil {
+			return err
+		}
+		followers = append(followers, page...)
+		if len(page) < opts.PageSize {
+			break
+		}
+	}
+
+	for _, target := range following {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		endpoint := "feed/" + feed.FeedSlug + "/" + feed.UserID + "/following/" + target.FeedID().Value() + "/"
+		err := c.del(feed, endpoint, nil, nil)
+		if err != nil {
+			return err
+		}
+		report.Follows = append(report.Follows, PurgedFollow{
+			Source: feed.FeedID(),
+			Target: target.FeedID(),
+		})
+	}
+
+	for _, follower := range followers {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := follower.Unfollow(c, feed)
+		if err != nil {
+			return err
+		}
+		report.Follows = append(report.Follows, PurgedFollow{
+			Source: follower.FeedID(),
+			Target: feed.FeedID(),
+		})
+	}
+
+	return nil
+}
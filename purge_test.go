@@ -0,0 +1,168 @@
+// +build go1.7
+
+package getstream_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestClientPurgeUser(t *testing.T) {
+	api := newFakeAPI()
+	for i := 1; i <= 5; i++ {
+		api.add("user:bob", map[string]interface{}{"actor": "bob", "verb": "post", "object": fmt.Sprintf("post:%d", i), "foreign_id": fmt.Sprintf("post:%d", i)})
+	}
+	api.add("user:alice", map[string]interface{}{"actor": "alice", "verb": "post", "object": "post:6"})
+	api.follows["timeline:bob"] = []string{"user:alice", "user:bob"}
+	api.follows["timeline:alice"] = []string{"user:bob"}
+
+	client, server, err := PreTestSetupWithServer(api.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	report, err := client.PurgeUserWithOptions(context.Background(), "bob", []string{"user", "timeline"}, &getstream.PurgeUserOptions{
+		ByForeignID: true,
+		PageSize:    2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Feeds) != 2 || report.Feeds[0] != "user:bob" || report.UserID != "bob" {
+		t.Fatal("Unexpected feeds:", report.Feeds)
+	}
+	if len(report.Activities) != 5 || report.Activities[0].ForeignID != "post:5" || report.Activities[0].Feed != "user:bob" {
+		t.Fatal("Unexpected activities:", report.Activities)
+	}
+	expected := []getstream.PurgedFollow{
+		{Source: "timeline:alice", Target: "user:bob"},
+		{Source: "timeline:bob", Target: "user:bob"},
+		{Source: "timeline:bob", Target: "user:alice"},
+	}
+	if fmt.Sprint(report.Follows) != fmt.Sprint(expected) {
+		t.Fatal("Unexpected follows:", report.Follows)
+	}
+	if report.UserDeleted || report.FinishedAt.Before(report.StartedAt) {
+		t.Fatal("Unexpected report:", report)
+	}
+
+	if len(api.activities["user:bob"]) != 0 || len(api.activities["user:alice"]) != 1 {
+		t.Fatal("Expected only the activities of bob to be removed, got:", api.activities)
+	}
+	if len(api.follows["timeline:bob"]) != 0 || len(api.follows["timeline:alice"]) != 0 {
+		t.Fatal("Expected the follows of bob to be removed, got:", api.follows)
+	}
+	for _, request := range api.requests {
+		if request == "DELETE feed/user/bob/post:1/" {
+			return
+		}
+	}
+	t.Fatal("Expected activities to be removed by foreign id, got:", api.requests)
+}
+
+func TestClientPurgeUserDeleteUser(t *testing.T) {
+	api := newFakeAPI()
+	api.add("user:bob", map[string]interface{}{"actor": "bob", "verb": "post", "object": "post:1"})
+
+	var deleted *http.Request
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1.0/user/bob/" {
+			deleted = r
+			w.Write([]byte(`{}`))
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	report, err := client.PurgeUserWithOptions(context.Background(), "bob", []string{"user"}, &getstream.PurgeUserOptions{DeleteUser: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.UserDeleted || len(report.Activities) != 1 || report.Activities[0].ForeignID != "" {
+		t.Fatal("Unexpected report:", report)
+	}
+	if deleted == nil || deleted.Method != "DELETE" {
+		t.Fatal("Expected the user to be deleted")
+	}
+	claims := tokenClaims(deleted.Header.Get("Authorization"))
+	if claims["resource"] != "users" || claims["action"] != "delete" || claims["user_id"] != "bob" {
+		t.Fatal("Unexpected token:", claims)
+	}
+}
+
+func TestClientPurgeUserStops(t *testing.T) {
+	api := newFakeAPI()
+	api.add("user:bob", map[string]interface{}{"actor": "bob", "verb": "post", "object": "post:1"})
+
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			// pretend the removal worked, without removing anything
+			w.Write([]byte(`{}`))
+			return
+		}
+		api.ServeHTTP(w, r)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	report, err := client.PurgeUser(context.Background(), "bob", []string{"user"})
+	if err == nil {
+		t.Fatal("Expected an error when activities are not removed")
+	}
+	if len(report.Activities) != 1 {
+		t.Fatal("Expected the report to hold the removed activity, got:", report.Activities)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.PurgeUser(ctx, "bob", []string{"user"})
+	if err != context.Canceled {
+		t.Fatal("Expected the purge to stop when the context is done, got:", err)
+	}
+
+	_, err = client.PurgeUser(context.Background(), "bob", nil)
+	if err == nil {
+		t.Fatal("Expected an error without feed slugs")
+	}
+}
+
+func TestClientPurgeUserCancelsRequest(t *testing.T) {
+	release := make(chan struct{})
+	client, server, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(2 * time.Second):
+		}
+		w.Write([]byte(`{"results": []}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = client.PurgeUser(ctx, "bob", []string{"user"})
+	if err != context.DeadlineExceeded {
+		t.Fatal("Expected the purge to stop at the deadline, got:", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("Expected the request in flight to be cancelled, took:", time.Since(start))
+	}
+}
@@ -0,0 +1,67 @@
+package getstream
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of the requests of a Client, add its Interceptor to
+// Client.Interceptors to enable it
+// It is a token bucket: up to burst requests are sent at once, then requestsPerSecond
+type RateLimiter struct {
+	rate  float64
+	burst float64
+
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing requestsPerSecond, with bursts of up to burst requests,
+// a rate of 0 doesn't limit requests
+func NewRateLimiter(requestsPerSecond float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   requestsPerSecond,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent
+func (l *RateLimiter) Wait() {
+	if l.rate <= 0 {
+		return
+	}
+
+	l.lock.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	// the token is taken right away, waiting requests queue up as a debt
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.lock.Unlock()
+
+	time.Sleep(wait)
+}
+
+// Interceptor returns the Interceptor waiting for the RateLimiter before every request
+func (l *RateLimiter) Interceptor() Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			l.Wait()
+			return next(req)
+		}
+	}
+}
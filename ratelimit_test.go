@@ -0,0 +1,29 @@
+package getstream_test
+
+import (
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestRateLimiter(t *testing.T) {
+	limiter := getstream.NewRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		limiter.Wait()
+	}
+	elapsed := time.Since(start)
+
+	// the burst goes through at once, the two requests after it wait 10ms each
+	if elapsed < 15*time.Millisecond || elapsed > 200*time.Millisecond {
+		t.Fatal("Unexpected wait:", elapsed)
+	}
+
+	start = time.Now()
+	getstream.NewRateLimiter(0, 0).Wait()
+	if time.Since(start) > 5*time.Millisecond {
+		t.Fatal("Expected a rate of 0 not to limit")
+	}
+}
@@ -0,0 +1,238 @@
+// +build go1.7
+
+package getstream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var (
+	realtimeMinBackoff = 500 * time.Millisecond
+	realtimeMaxBackoff = 30 * time.Second
+	// realtimeReadTimeout bounds the wait for a reply until the server advises its long-poll timeout,
+	// so that a half-open connection is dropped and reconnected
+	realtimeReadTimeout = 90 * time.Second
+)
+
+// RealtimeError is returned by Subscribe when GetStream.io rejects the subscription
+// This is not retried since it usually means the credentials are wrong
+type RealtimeError struct {
+	Channel string
+	Message string
+}
+
+func (e *RealtimeError) Error() string {
+	return "realtime subscription to " + e.Channel + " failed: " + e.Message
+}
+
+// bayeuxMessage is a Faye/Bayeux protocol message
+type bayeuxMessage struct {
+	Channel                  string            `json:"channel"`
+	ID                       string            `json:"id,omitempty"`
+	ClientID                 string            `json:"clientId,omitempty"`
+	Version                  string            `json:"version,omitempty"`
+	SupportedConnectionTypes []string          `json:"supportedConnectionTypes,omitempty"`
+	ConnectionType           string            `json:"connectionType,omitempty"`
+	Subscription             string            `json:"subscription,omitempty"`
+	Successful               bool              `json:"successful,omitempty"`
+	Error                    string            `json:"error,omitempty"`
+	Advice                   *bayeuxAdvice     `json:"advice,omitempty"`
+	Ext                      map[string]string `json:"ext,omitempty"`
+	Data                     json.RawMessage   `json:"data,omitempty"`
+}
+
+type bayeuxAdvice struct {
+	Reconnect string `json:"reconnect,omitempty"`
+	Interval  int    `json:"interval,omitempty"`
+	Timeout   int    `json:"timeout,omitempty"`
+}
+
+// Subscribe listens for real-time updates of a Feed and calls handler for every FeedUpdate
+// It blocks until ctx is done, reconnecting with backoff when the connection drops,
+// and returns ctx.Err() or a *RealtimeError when the subscription is rejected
+func (c *Client) Subscribe(ctx context.Context, feed Feed, handler func(*FeedUpdate)) error {
+	if c.Config.AppID == "" {
+		return errors.New("AppID is required for realtime subscriptions")
+	}
+
+	token, err := c.Signer.GenerateFeedScopeToken(ScopeContextFeed, ScopeActionRead, feed.FeedIDWithoutColon())
+	if err != nil {
+		return err
+	}
+
+	session := &realtimeSession{
+		client:  c,
+		channel: "/" + realtimeChannel(c.Config.AppID, feed),
+		token:   token,
+		handler: handler,
+	}
+
+	backoff := realtimeMinBackoff
+	for {
+		subscribed, err := session.run(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if _, ok := err.(*RealtimeError); ok {
+			return err
+		}
+
+		if subscribed {
+			backoff = realtimeMinBackoff
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > realtimeMaxBackoff {
+			backoff = realtimeMaxBackoff
+		}
+	}
+}
+
+// realtimeChannel is the name GetStream.io publishes the updates of a Feed on, site-<appId>-feed-<slug><id>
+func realtimeChannel(appID string, feed Feed) string {
+	return "site-" + appID + "-feed-" + feed.FeedIDWithoutColon()
+}
+
+// realtimeSession is a single subscription to a Feed channel
+type realtimeSession struct {
+	client  *Client
+	channel string
+	token   string
+	handler func(*FeedUpdate)
+
+	conn        *websocket.Conn
+	clientID    string
+	nextID      int
+	readTimeout time.Duration
+}
+
+// run connects, subscribes and dispatches updates until the connection is lost
+// subscribed reports whether the subscription was set up, which resets the backoff
+func (s *realtimeSession) run(ctx context.Context) (subscribed bool, err error) {
+	dialer := websocket.Dialer{
+		HandshakeTimeout: s.client.Config.TimeoutDuration,
+	}
+
+	conn, _, err := dialer.DialContext(ctx, s.client.RealtimeURL.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	s.conn = conn
+	s.clientID = ""
+	s.readTimeout = realtimeReadTimeout
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+		conn.Close()
+	}()
+
+	reply, err := s.call(&bayeuxMessage{
+		Channel:                  "/meta/handshake",
+		Version:                  "1.0",
+		SupportedConnectionTypes: []string{"websocket"},
+	})
+	if err != nil {
+		return false, err
+	}
+	if !reply.Successful {
+		return false, errors.New("realtime handshake failed: " + reply.Error)
+	}
+	s.clientID = reply.ClientID
+
+	reply, err = s.call(&bayeuxMessage{
+		Channel:      "/meta/subscribe",
+		Subscription: s.channel,
+		Ext: map[string]string{
+			"user_id":   s.channel[1:],
+			"api_key":   s.client.Config.APIKey,
+			"signature": s.token,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	if !reply.Successful {
+		return false, &RealtimeError{Channel: s.channel, Message: reply.Error}
+	}
+
+	for {
+		reply, err = s.call(&bayeuxMessage{
+			Channel:        "/meta/connect",
+			ConnectionType: "websocket",
+		})
+		if err != nil {
+			return true, err
+		}
+		if reply.Advice != nil && reply.Advice.Reconnect == "handshake" {
+			return true, errors.New("realtime server requested a new handshake")
+		}
+		if !reply.Successful {
+			return true, errors.New("realtime connect failed: " + reply.Error)
+		}
+	}
+}
+
+// call sends a message and waits for the reply on the same channel
+// updates for the subscribed Feed arriving in the meantime are dispatched to the handler
+func (s *realtimeSession) call(message *bayeuxMessage) (*bayeuxMessage, error) {
+	s.nextID++
+	message.ID = strconv.Itoa(s.nextID)
+	message.ClientID = s.clientID
+
+	err := s.conn.WriteJSON([]*bayeuxMessage{message})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err = s.conn.SetReadDeadline(time.Now().Add(s.readTimeout))
+		if err != nil {
+			return nil, err
+		}
+
+		var replies []*bayeuxMessage
+		err = s.conn.ReadJSON(&replies)
+		if err != nil {
+			return nil, err
+		}
+
+		var result *bayeuxMessage
+		for _, reply := range replies {
+			switch {
+			case reply.Channel == message.Channel && (reply.ID == "" || reply.ID == message.ID):
+				result = reply
+				if reply.Advice != nil && reply.Advice.Timeout > 0 {
+					// the server holds /meta/connect for up to its timeout, half as much again is left for the network
+					timeout := time.Duration(reply.Advice.Timeout) * time.Millisecond
+					s.readTimeout = timeout + timeout/2
+				}
+			case reply.Channel == s.channel && reply.Data != nil:
+				update := &FeedUpdate{}
+				if err := json.Unmarshal(reply.Data, update); err != nil {
+					continue
+				}
+				s.handler(update)
+			}
+		}
+		if result != nil {
+			return result, nil
+		}
+	}
+}
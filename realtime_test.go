@@ -0,0 +1,242 @@
+// +build go1.7
+
+package getstream_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+	"github.com/gorilla/websocket"
+)
+
+// bayeuxStandIn is a minimal Faye server: it accepts handshakes and subscriptions
+// and pushes the given update to the subscribed channel on the first connect
+type bayeuxStandIn struct {
+	sync.Mutex
+
+	update        string
+	rejectWith    string
+	dropFirst     bool
+	hangFirst     bool
+	adviceTimeout int
+	handshakes    int
+	subscriptions []map[string]interface{}
+}
+
+func (b *bayeuxStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	b.Lock()
+	b.handshakes++
+	drop := b.dropFirst && b.handshakes == 1
+	hang := b.hangFirst && b.handshakes == 1
+	b.Unlock()
+
+	channel := ""
+	connects := 0
+	for {
+		var messages []map[string]interface{}
+		if err := conn.ReadJSON(&messages); err != nil {
+			return
+		}
+
+		for _, message := range messages {
+			reply := map[string]interface{}{
+				"channel":    message["channel"],
+				"id":         message["id"],
+				"successful": true,
+			}
+
+			switch message["channel"] {
+			case "/meta/handshake":
+				reply["clientId"] = "client1"
+				if b.adviceTimeout > 0 {
+					reply["advice"] = map[string]interface{}{"reconnect": "retry", "timeout": b.adviceTimeout}
+				}
+			case "/meta/subscribe":
+				b.Lock()
+				b.subscriptions = append(b.subscriptions, message)
+				b.Unlock()
+
+				channel = message["subscription"].(string)
+				if b.rejectWith != "" {
+					reply["successful"] = false
+					reply["error"] = b.rejectWith
+				}
+			case "/meta/connect":
+				connects++
+				if drop {
+					return
+				}
+				if hang {
+					// never reply, like a connection which went away without being closed
+					continue
+				}
+				if connects > 1 {
+					// hold the long-poll open like Faye does
+					time.Sleep(time.Second)
+					continue
+				}
+				push := `[{"channel": "` + channel + `", "data": ` + b.update + `}, {"channel": "/meta/connect", "id": "` + message["id"].(string) + `", "successful": true}]`
+				if err := conn.WriteMessage(websocket.TextMessage, []byte(push)); err != nil {
+					return
+				}
+				continue
+			}
+
+			if err := conn.WriteJSON([]interface{}{reply}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestClientSubscribe(t *testing.T) {
+	standIn := &bayeuxStandIn{
+		update: `{"feed": "flat:bob", "new": [{"id": "1", "actor": "bob", "verb": "post", "object": "post:1"}], "deleted": ["2"]}`,
+	}
+	client, server, err := PreTestSetupWithServer(standIn.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var update *getstream.FeedUpdate
+	err = client.Subscribe(ctx, feed, func(u *getstream.FeedUpdate) {
+		update = u
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatal("Expected Subscribe to stop when the context is cancelled, got:", err)
+	}
+
+	if update == nil {
+		t.Fatal("Expected an update")
+	}
+	if update.Feed != "flat:bob" || len(update.New) != 1 || update.New[0].Verb != "post" || len(update.Deleted) != 1 || update.Deleted[0] != "2" {
+		t.Fatal("Unexpected update:", update)
+	}
+
+	subscription := standIn.subscriptions[0]
+	if subscription["subscription"] != "/site-111111-feed-flatbob" {
+		t.Fatal("Expected subscription to /site-111111-feed-flatbob, got:", subscription["subscription"])
+	}
+	ext := subscription["ext"].(map[string]interface{})
+	if ext["user_id"] != "site-111111-feed-flatbob" || ext["api_key"] != "my_key" || ext["signature"] == "" {
+		t.Fatal("Unexpected subscription ext:", ext)
+	}
+}
+
+func TestClientSubscribeReconnects(t *testing.T) {
+	standIn := &bayeuxStandIn{
+		update:    `{"feed": "flat:bob", "new": [], "deleted": ["1"]}`,
+		dropFirst: true,
+	}
+	client, server, err := PreTestSetupWithServer(standIn.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := false
+	err = client.Subscribe(ctx, feed, func(u *getstream.FeedUpdate) {
+		received = true
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatal("Expected Subscribe to stop when the context is cancelled, got:", err)
+	}
+
+	if !received {
+		t.Fatal("Expected an update after reconnecting")
+	}
+	if standIn.handshakes != 2 {
+		t.Fatal("Expected 2 handshakes, got:", standIn.handshakes)
+	}
+}
+
+func TestClientSubscribeHalfOpen(t *testing.T) {
+	standIn := &bayeuxStandIn{
+		update:        `{"feed": "flat:bob", "new": [], "deleted": ["1"]}`,
+		hangFirst:     true,
+		adviceTimeout: 100,
+	}
+	client, server, err := PreTestSetupWithServer(standIn.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	received := false
+	err = client.Subscribe(ctx, feed, func(u *getstream.FeedUpdate) {
+		received = true
+		cancel()
+	})
+	if err != context.Canceled {
+		t.Fatal("Expected Subscribe to stop when the context is cancelled, got:", err)
+	}
+
+	if !received {
+		t.Fatal("Expected an update after the unanswered connect timed out")
+	}
+	if standIn.handshakes != 2 {
+		t.Fatal("Expected 2 handshakes, got:", standIn.handshakes)
+	}
+}
+
+func TestClientSubscribeRejected(t *testing.T) {
+	standIn := &bayeuxStandIn{
+		rejectWith: "403::Forbidden",
+	}
+	client, server, err := PreTestSetupWithServer(standIn.ServeHTTP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err = client.Subscribe(ctx, feed, func(u *getstream.FeedUpdate) {})
+	if _, ok := err.(*getstream.RealtimeError); !ok {
+		t.Fatal("Expected a RealtimeError, got:", err)
+	}
+}
@@ -0,0 +1,178 @@
+package getstream
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Locations lists the regions the feed API runs in
+var Locations = []string{"us-east", "us-west", "eu-west", "eu-central", "singapore", "tokyo"}
+
+// Region is the feed API endpoint of a location
+type Region struct {
+	Name    string
+	BaseURL *url.URL
+}
+
+// Region returns the Region of a location such as "us-east", for the API version of the Client
+func (c *Client) Region(location string) (*Region, error) {
+	baseURL, err := locationURL(location, c.Config.Version)
+	if err != nil {
+		return nil, err
+	}
+	return &Region{Name: location, BaseURL: baseURL}, nil
+}
+
+// Regions returns the Region of every one of the Locations
+func (c *Client) Regions() ([]*Region, error) {
+	var regions []*Region
+	for _, location := range Locations {
+		region, err := c.Region(location)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, region)
+	}
+	return regions, nil
+}
+
+// RegionFailover sends the requests of a Client to the feed API in the preferred region, add its
+// Interceptor to Client.Interceptors to enable it
+// GET requests which fail without a response or with a 5xx response are sent to the next regions in
+// order, other requests are only sent to the preferred region. Response.Region is the region which served it
+type RegionFailover struct {
+	client *Client
+
+	lock    sync.RWMutex
+	regions []*Region
+}
+
+// NewRegionFailover returns a RegionFailover over the regions, in order of preference
+func NewRegionFailover(client *Client, regions ...*Region) *RegionFailover {
+	return &RegionFailover{
+		client:  client,
+		regions: regions,
+	}
+}
+
+// Regions returns the regions in order of preference
+func (f *RegionFailover) Regions() []*Region {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return append([]*Region{}, f.regions...)
+}
+
+// Prefer makes the named region the preferred one
+func (f *RegionFailover) Prefer(name string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for i, region := range f.regions {
+		if region.Name == name {
+			regions := append([]*Region{region}, f.regions[:i]...)
+			f.regions = append(regions, f.regions[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("Unknown region " + name)
+}
+
+// PreferNearest orders the regions by the time they take to answer a request, the regions which
+// can't be reached come last, and returns the preferred one
+func (f *RegionFailover) PreferNearest() (*Region, error) {
+	regions := f.Regions()
+	latencies := make([]time.Duration, len(regions))
+
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, region *Region) {
+			defer wg.Done()
+
+			start := time.Now()
+			resp, err := f.client.HTTP.Get(region.BaseURL.String())
+			if err != nil {
+				latencies[i] = -1
+				return
+			}
+			resp.Body.Close()
+			latencies[i] = time.Since(start)
+		}(i, region)
+	}
+	wg.Wait()
+
+	sort.Sort(&byLatency{regions: regions, latencies: latencies})
+	if len(regions) == 0 || latencies[0] < 0 {
+		return nil, errors.New("No region could be reached")
+	}
+
+	f.lock.Lock()
+	f.regions = regions
+	f.lock.Unlock()
+
+	return regions[0], nil
+}
+
+type byLatency struct {
+	regions   []*Region
+	latencies []time.Duration
+}
+
+func (a *byLatency) Len() int { return len(a.regions) }
+func (a *byLatency) Swap(i, j int) {
+	a.regions[i], a.regions[j] = a.regions[j], a.regions[i]
+	a.latencies[i], a.latencies[j] = a.latencies[j], a.latencies[i]
+}
+func (a *byLatency) Less(i, j int) bool {
+	if a.latencies[i] < 0 || a.latencies[j] < 0 {
+		return a.latencies[j] < 0 && a.latencies[i] >= 0
+	}
+	return a.latencies[i] < a.latencies[j]
+}
+
+// Interceptor returns the Interceptor sending the feed API requests to the regions
+// Every region is tried once, with the location param of the region it is sent to
+// Place RetryInterceptor after it to retry in a region before failing over
+func (f *RegionFailover) Interceptor() Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(req *Request) (*Response, error) {
+			// the analytics and personalization APIs aren't regional
+			if req.BaseURL != f.client.BaseURL {
+				return next(req)
+			}
+			defer func(baseURL *url.URL, params map[string]string) {
+				req.BaseURL = baseURL
+				req.Params = params
+			}(req.BaseURL, req.Params)
+			params := req.Params
+
+			var resp *Response
+			var err error
+			regions := f.Regions()
+			for i, region := range regions {
+				req.BaseURL = region.BaseURL
+				if req.Auth != AuthJWT {
+					req.Params = map[string]string{}
+					for key, value := range params {
+						req.Params[key] = value
+					}
+					req.Params["location"] = region.Name
+				}
+				resp, err = next(req)
+				if resp != nil {
+					resp.Region = region.Name
+				}
+
+				failed := err != nil && (resp == nil || resp.StatusCode >= 500)
+				if req.Method != "GET" || !failed || i == len(regions)-1 {
+					break
+				}
+			}
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package getstream_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func testRegion(t *testing.T, name string, server *httptest.Server) *getstream.Region {
+	baseURL, err := url.Parse(server.URL + "/api/v1.0/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &getstream.Region{Name: name, BaseURL: baseURL}
+}
+
+func TestClientRegion(t *testing.T) {
+	client, err := getstream.New(&getstream.Config{APIKey: "my_key", APISecret: "my_secret", AppID: "111111"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	region, err := client.Region("eu-west")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if region.BaseURL.String() != "https://eu-west-api.getstream.io/api/v1.0/" {
+		t.Fatal("Unexpected region URL:", region.BaseURL)
+	}
+	regions, err := client.Regions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regions) != len(getstream.Locations) {
+		t.Fatal("Expected a region for every location, got:", regions)
+	}
+}
+
+func TestRegionFailover(t *testing.T) {
+	primaryCalls := 0
+	var locations []string
+	client, primary, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		primaryCalls++
+		locations = append(locations, r.URL.Query().Get("location"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code": 0, "exception": "ServiceUnavailable"}`))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locations = append(locations, r.URL.Query().Get("location"))
+		w.Write([]byte(`{"results": []}`))
+	}))
+	defer secondary.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	failover := getstream.NewRegionFailover(client, testRegion(t, "us-east", primary), testRegion(t, "us-west", down), testRegion(t, "eu-west", secondary))
+	var regions []string
+	client.Interceptors = []getstream.Interceptor{
+		getstream.MetricsInterceptor(getstream.MetricsFunc(func(m *getstream.RequestMetrics) {
+			regions = append(regions, m.Region)
+		})),
+		failover.Interceptor(),
+	}
+
+	feed, err := client.FlatFeed("user", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.AddActivity(&getstream.Activity{Actor: "bob", Verb: "post", Object: "post:1"})
+	if err == nil {
+		t.Fatal("Expected writes not to fail over")
+	}
+	if primaryCalls != 2 || len(regions) != 2 || regions[0] != "eu-west" || regions[1] != "us-east" {
+		t.Fatal("Unexpected regions:", primaryCalls, regions)
+	}
+	if len(locations) != 3 || locations[0] != "us-east" || locations[1] != "eu-west" || locations[2] != "us-east" {
+		t.Fatal("Expected the location param of every region tried, got:", locations)
+	}
+
+	err = failover.Prefer("eu-west")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = feed.Activities(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primaryCalls != 2 || regions[2] != "eu-west" {
+		t.Fatal("Expected the preferred region to serve the read, got:", primaryCalls, regions)
+	}
+	if failover.Prefer("mars") == nil {
+		t.Fatal("Expected an unknown region to be refused")
+	}
+}
+
+func TestRegionFailoverPreferNearest(t *testing.T) {
+	client, slow, err := PreTestSetupWithServer(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer slow.Close()
+	fast := httptest.NewServer(http.NotFoundHandler())
+	defer fast.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	failover := getstream.NewRegionFailover(client, testRegion(t, "us-west", down), testRegion(t, "us-east", slow), testRegion(t, "eu-west", fast))
+	nearest, err := failover.PreferNearest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, region := range failover.Regions() {
+		names = append(names, region.Name)
+	}
+	if nearest.Name != "eu-west" || len(names) != 3 || names[1] != "us-east" || names[2] != "us-west" {
+		t.Fatal("Unexpected region order:", names)
+	}
+}
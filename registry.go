@@ -0,0 +1,201 @@
+package getstream
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AppConfig is a named application of a Registry
+type AppConfig struct {
+	Name   string
+	Config Config
+	// RequestsPerSecond and Burst configure the RateLimiter of the app Client, 0 doesn't limit requests
+	RequestsPerSecond float64
+	Burst             int
+	// Tenants are the names the app is looked up by with Registry.ClientForTenant
+	Tenants []string
+}
+
+// ConfigSource loads the applications of a Registry, such as from a file or a secrets store
+type ConfigSource interface {
+	Load() ([]*AppConfig, error)
+}
+
+// ConfigSourceFunc adapts a function to a ConfigSource
+type ConfigSourceFunc func() ([]*AppConfig, error)
+
+// Load calls f()
+func (f ConfigSourceFunc) Load() ([]*AppConfig, error) {
+	return f()
+}
+
+// Registry holds the Clients of several applications, by name and tenant
+// Clients are built when first used, each with its own transport and RateLimiter, and are
+// built again after a Reload changes their AppConfig
+type Registry struct {
+	source ConfigSource
+
+	// Setup, when set, is called with every Client built, to add Interceptors or a Logger,
+	// it must not use the Registry
+	Setup func(app *AppConfig, client *Client)
+
+	lock    sync.Mutex
+	apps    map[string]*AppConfig
+	tenants map[string]string
+	clients map[string]*Client
+}
+
+// NewRegistry returns a Registry of the applications loaded from source
+func NewRegistry(source ConfigSource) (*Registry, error) {
+	registry := &Registry{
+		source:  source,
+		apps:    map[string]*AppConfig{},
+		tenants: map[string]string{},
+		clients: map[string]*Client{},
+	}
+	err := registry.Reload()
+	if err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Reload loads the applications again, the Clients of the applications which changed or were removed
+// are dropped, those still in use keep working with the previous credentials
+// On error the applications loaded before are kept
+func (r *Registry) Reload() error {
+	loaded, err := r.source.Load()
+	if err != nil {
+		return err
+	}
+
+	apps := map[string]*AppConfig{}
+	tenants := map[string]string{}
+	for _, app := range loaded {
+		if app.Name == "" {
+			return errors.New("App name was not set")
+		}
+		if _, ok := apps[app.Name]; ok {
+			return errors.New("Duplicate app " + app.Name)
+		}
+		apps[app.Name] = app
+		for _, tenant := range app.Tenants {
+			if other, ok := tenants[tenant]; ok {
+				return errors.New("Tenant " + tenant + " belongs to both " + other + " and " + app.Name)
+			}
+			tenants[tenant] = app.Name
+		}
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for name, client := range r.clients {
+		app, ok := apps[name]
+		if ok && sameClientConfig(r.apps[name], app) {
+			continue
+		}
+		delete(r.clients, name)
+		if transport, ok := client.HTTP.Transport.(*http.Transport); ok {
+			transport.CloseIdleConnections()
+		}
+	}
+	r.apps = apps
+	r.tenants = tenants
+
+	return nil
+}
+
+// sameClientConfig tells whether a Client built for the old AppConfig can be kept for the new one
+func sameClientConfig(old *AppConfig, app *AppConfig) bool {
+	return reflect.DeepEqual(old.Config, app.Config) &&
+		old.RequestsPerSecond == app.RequestsPerSecond &&
+		old.Burst == app.Burst
+}
+
+// Watch calls Reload every interval until the returned function is called,
+// onError, when set, receives the errors of Reload
+func (r *Registry) Watch(interval time.Duration, onError func(err error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				err := r.Reload()
+				if err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			ticker.Stop()
+			close(done)
+		})
+	}
+}
+
+// Apps returns the names of the applications
+func (r *Registry) Apps() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var names []string
+	for name := range r.apps {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Client returns the Client of the named application
+func (r *Registry) Client(name string) (*Client, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if client, ok := r.clients[name]; ok {
+		return client, nil
+	}
+	app, ok := r.apps[name]
+	if !ok {
+		return nil, errors.New("Unknown app " + name)
+	}
+
+	client, err := New(&app.Config)
+	if err != nil {
+		return nil, err
+	}
+	client.HTTP = &http.Client{
+		Transport: newTransport(),
+		Timeout:   client.Config.TimeoutDuration,
+	}
+	if app.RequestsPerSecond > 0 {
+		client.Interceptors = append(client.Interceptors, NewRateLimiter(app.RequestsPerSecond, app.Burst).Interceptor())
+	}
+	if r.Setup != nil {
+		r.Setup(app, client)
+	}
+
+	r.clients[name] = client
+	return client, nil
+}
+
+// ClientForTenant returns the Client of the application the tenant belongs to
+func (r *Registry) ClientForTenant(tenant string) (*Client, error) {
+	r.lock.Lock()
+	name, ok := r.tenants[tenant]
+	r.lock.Unlock()
+
+	if !ok {
+		return nil, errors.New("Unknown tenant " + tenant)
+	}
+	return r.Client(name)
+}
@@ -0,0 +1,128 @@
+package getstream_test
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestRegistry(t *testing.T) {
+	apps := []*getstream.AppConfig{
+		{Name: "social", Config: getstream.Config{APIKey: "social_key", APISecret: "social_secret", AppID: "1"}, Tenants: []string{"acme", "globex"}},
+		{Name: "news", Config: getstream.Config{APIKey: "news_key", APISecret: "news_secret", AppID: "2"}, RequestsPerSecond: 10},
+	}
+	var fail error
+	var lock sync.Mutex
+	source := getstream.ConfigSourceFunc(func() ([]*getstream.AppConfig, error) {
+		lock.Lock()
+		defer lock.Unlock()
+		return apps, fail
+	})
+
+	registry, err := getstream.NewRegistry(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var built []string
+	registry.Setup = func(app *getstream.AppConfig, client *getstream.Client) {
+		built = append(built, app.Name)
+	}
+
+	names := registry.Apps()
+	sort.Strings(names)
+	if len(names) != 2 || names[0] != "news" || names[1] != "social" {
+		t.Fatal("Unexpected apps:", names)
+	}
+
+	social, err := registry.ClientForTenant("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := registry.Client("social")
+	if err != nil {
+		t.Fatal(err)
+	}
+	news, err := registry.Client("news")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if social != again || social.Config.APIKey != "social_key" || len(built) != 2 {
+		t.Fatal("Expected clients to be built once, got:", built)
+	}
+	if social.HTTP.Transport == news.HTTP.Transport || social.HTTP.Transport == http.RoundTripper(getstream.GETSTREAM_TRANSPORT) {
+		t.Fatal("Expected every client to have its own transport")
+	}
+	if len(news.Interceptors) != 1 || len(social.Interceptors) != 0 {
+		t.Fatal("Expected only the news client to be rate limited")
+	}
+	if apps[0].Config.Version != "" {
+		t.Fatal("Expected the app config to be left as it is")
+	}
+
+	if _, err = registry.ClientForTenant("initech"); err == nil {
+		t.Fatal("Expected an unknown tenant to fail")
+	}
+	if _, err = registry.Client("chat"); err == nil {
+		t.Fatal("Expected an unknown app to fail")
+	}
+
+	// rotating the secret of social rebuilds its client only
+	lock.Lock()
+	apps = []*getstream.AppConfig{
+		{Name: "social", Config: getstream.Config{APIKey: "social_key", APISecret: "rotated_secret", AppID: "1"}, Tenants: []string{"acme"}},
+		apps[1],
+	}
+	lock.Unlock()
+	stop := registry.Watch(5*time.Millisecond, nil)
+	defer stop()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		client, err := registry.ClientForTenant("acme")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if client.Config.APISecret == "rotated_secret" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the secret to be reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+
+	if client, _ := registry.Client("news"); client != news {
+		t.Fatal("Expected the unchanged client to be kept")
+	}
+	if _, err = registry.ClientForTenant("globex"); err == nil {
+		t.Fatal("Expected the removed tenant to be unknown")
+	}
+
+	lock.Lock()
+	fail = errors.New("unavailable")
+	lock.Unlock()
+	if registry.Reload() == nil {
+		t.Fatal("Expected the reload to fail")
+	}
+	if _, err = registry.ClientForTenant("acme"); err != nil {
+		t.Fatal("Expected the apps to be kept after a failed reload, got:", err)
+	}
+}
+
+func TestRegistryInvalidApps(t *testing.T) {
+	_, err := getstream.NewRegistry(getstream.ConfigSourceFunc(func() ([]*getstream.AppConfig, error) {
+		return []*getstream.AppConfig{
+			{Name: "social", Tenants: []string{"acme"}},
+			{Name: "news", Tenants: []string{"acme"}},
+		}, nil
+	}))
+	if err == nil {
+		t.Fatal("Expected a tenant of two apps to fail")
+	}
+}
@@ -42,6 +42,12 @@ const (
 	ScopeContextFollower ScopeContext = 4
 	// ScopeContextAll : Allow access to any resource
 	ScopeContextAll ScopeContext = 8
+	// ScopeContextAnalytics : Analytics Endpoint
+	ScopeContextAnalytics ScopeContext = 16
+	// ScopeContextPersonalization : Personalization Endpoint
+	ScopeContextPersonalization ScopeContext = 32
+	// ScopeContextUsers : Users Endpoint
+	ScopeContextUsers ScopeContext = 64
 )
 
 // Value returns a string representation
@@ -55,6 +61,12 @@ func (a ScopeContext) Value() string {
 		return "follower"
 	case 8:
 		return "*"
+	case 16:
+		return "analytics"
+	case 32:
+		return "personalization"
+	case 64:
+		return "users"
 	default:
 		return ""
 	}
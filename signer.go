@@ -3,7 +3,9 @@ package getstream
 import (
 	"crypto/hmac"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"strings"
 
 	"gopkg.in/dgrijalva/jwt-go.v3"
@@ -39,6 +41,20 @@ func (s Signer) GenerateToken(message string) string {
 	return s.UrlSafe(digest)
 }
 
+// SignPayload returns the hex encoded HMAC-SHA256 of a payload, as sent by GetStream.io
+// in the X-Signature header of callback requests
+func (s Signer) SignPayload(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyPayload reports whether signature is the signature of the payload
+func (s Signer) VerifyPayload(payload []byte, signature string) bool {
+	expected := s.SignPayload(payload)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
 // GenerateFeedScopeToken returns a jwt
 func (s Signer) GenerateFeedScopeToken(context ScopeContext, action ScopeAction, feedIDWithoutColon string) (string, error) {
 
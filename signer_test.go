@@ -123,3 +123,26 @@ func TestUserScopeToken(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSignPayload(t *testing.T) {
+
+	signer := getstream.Signer{
+		Secret: "test_secret",
+	}
+
+	payload := []byte(`[{"feed":"flat:bob"}]`)
+	signature := signer.SignPayload(payload)
+	if len(signature) != 64 {
+		t.Fatal("Expected a hex encoded sha256 signature, got:", signature)
+	}
+
+	if !signer.VerifyPayload(payload, signature) {
+		t.Fatal("Expected signature to verify")
+	}
+	if signer.VerifyPayload([]byte(`[{"feed":"flat:eve"}]`), signature) {
+		t.Fatal("Expected signature of another payload not to verify")
+	}
+	if signer.VerifyPayload(payload, "") {
+		t.Fatal("Expected an empty signature not to verify")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"regexp"
 	"strings"
+	"time"
 )
 
 func ValidateFeedSlug(feedSlug string) (string, error) {
@@ -50,3 +51,35 @@ func ValidateUserID(userID string) (string, error) {
 
 	return userID, nil
 }
+
+// ValidateRanking checks the name of a ranking method (or ranking variable) as configured
+// on the GetStream.io dashboard. Unlike feed slugs the name is not rewritten, it has to match as is
+func ValidateRanking(ranking string) (string, error) {
+	r, err := regexp.Compile(`^[a-zA-Z0-9_]+$`)
+	if err != nil {
+		return "", err
+	}
+
+	if !r.MatchString(ranking) {
+		return "", errors.New("invalid ranking")
+	}
+
+	return ranking, nil
+}
+
+// parseTime parses a timestamp as returned by the API, which is usually without a timezone (UTC)
+func parseTime(value string) (time.Time, error) {
+	result, err := time.Parse("2006-01-02T15:04:05.999999", value)
+	if err == nil {
+		return result, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// formatTime formats a timestamp the way the API returns it, in UTC without a timezone, zero times are empty
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format("2006-01-02T15:04:05.999999")
+}
@@ -59,3 +59,23 @@ func TestUserID(t *testing.T) {
 		t.Error("userSlug not '1_2_3'")
 	}
 }
+
+func TestRanking(t *testing.T) {
+	ranking, err := getstream.ValidateRanking("popularity_v2")
+	if err != nil {
+		t.Error(err)
+	}
+	if ranking != "popularity_v2" {
+		t.Error("ranking not 'popularity_v2'")
+	}
+
+	_, err = getstream.ValidateRanking("pop-ularity")
+	if err == nil || err.Error() != "invalid ranking" {
+		t.Error("Expected invalid ranking error, got:", err)
+	}
+
+	_, err = getstream.ValidateRanking("")
+	if err == nil {
+		t.Error("Expected an empty ranking to be invalid")
+	}
+}
@@ -0,0 +1,98 @@
+package getstream
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+)
+
+// maxWebhookPayload limits the size of callback bodies that are read
+const maxWebhookPayload = 10 << 20
+
+// WebhookHandler is an http.Handler receiving the feed updates GetStream.io posts to a callback URL
+// Requests are verified with the X-Signature header using the Client's Signer,
+// so the Client needs to be set up with the API Secret rather than a Token
+type WebhookHandler struct {
+	Client *Client
+
+	lock          sync.RWMutex
+	callbacks     []func(*FeedUpdate)
+	feedCallbacks map[FeedID][]func(*FeedUpdate)
+}
+
+// WebhookHandler returns a WebhookHandler verifying requests with the Client's Signer
+func (c *Client) WebhookHandler() *WebhookHandler {
+	return &WebhookHandler{
+		Client: c,
+	}
+}
+
+// OnUpdate registers a callback for the updates of all Feeds
+func (h *WebhookHandler) OnUpdate(callback func(*FeedUpdate)) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.callbacks = append(h.callbacks, callback)
+}
+
+// OnFeedUpdate registers a callback for the updates of a single Feed
+func (h *WebhookHandler) OnFeedUpdate(feed Feed, callback func(*FeedUpdate)) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.feedCallbacks == nil {
+		h.feedCallbacks = make(map[FeedID][]func(*FeedUpdate))
+	}
+	h.feedCallbacks[feed.FeedID()] = append(h.feedCallbacks[feed.FeedID()], callback)
+}
+
+// ServeHTTP answers the GET verification request with the API Key,
+// and verifies, decodes and dispatches POSTed updates
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		// GetStream.io checks the callback URL by expecting the API Key back
+		w.Write([]byte(h.Client.Config.APIKey))
+		return
+	case "POST":
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return
+	}
+
+	if !h.Client.Signer.VerifyPayload(payload, r.Header.Get("X-Signature")) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var updates []*FeedUpdate
+	err = json.Unmarshal(payload, &updates)
+	if err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	h.dispatch(updates)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) dispatch(updates []*FeedUpdate) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	for _, update := range updates {
+		for _, callback := range h.callbacks {
+			callback(update)
+		}
+		for _, callback := range h.feedCallbacks[update.Feed] {
+			callback(update)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// +build go1.7
+
+package getstream_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	getstream "github.com/GetStream/stream-go"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := client.FlatFeed("flat", "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all []*getstream.FeedUpdate
+	var bobs []*getstream.FeedUpdate
+
+	handler := client.WebhookHandler()
+	handler.OnUpdate(func(update *getstream.FeedUpdate) {
+		all = append(all, update)
+	})
+	handler.OnFeedUpdate(feed, func(update *getstream.FeedUpdate) {
+		bobs = append(bobs, update)
+	})
+
+	payload := []byte(`[
+		{"feed": "flat:bob", "app_id": 111111, "new": [{"id": "1", "actor": "bob", "verb": "post", "object": "post:1", "time": "2016-09-22T21:44:58.821577"}], "deleted": []},
+		{"feed": "flat:eve", "app_id": 111111, "new": [], "deleted": ["2"]}
+	]`)
+
+	request := httptest.NewRequest("POST", "/callback", bytes.NewReader(payload))
+	request.Header.Set("X-Signature", client.Signer.SignPayload(payload))
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusOK {
+		t.Fatal("Expected status 200, got:", recorder.Code, recorder.Body.String())
+	}
+	if len(all) != 2 {
+		t.Fatal("Expected 2 updates, got:", all)
+	}
+	if len(bobs) != 1 || len(bobs[0].New) != 1 || bobs[0].New[0].TimeStamp == nil {
+		t.Fatal("Expected one decoded update for flat:bob, got:", bobs)
+	}
+	if all[1].Feed != "flat:eve" || all[1].Deleted[0] != "2" {
+		t.Fatal("Unexpected update for flat:eve:", all[1])
+	}
+}
+
+func TestWebhookHandlerInvalidSignature(t *testing.T) {
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	handler := client.WebhookHandler()
+	handler.OnUpdate(func(update *getstream.FeedUpdate) {
+		called = true
+	})
+
+	payload := []byte(`[{"feed": "flat:bob", "new": [], "deleted": ["1"]}]`)
+	request := httptest.NewRequest("POST", "/callback", bytes.NewReader(payload))
+	request.Header.Set("X-Signature", "not a signature")
+	recorder := httptest.NewRecorder()
+
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusUnauthorized {
+		t.Fatal("Expected status 401, got:", recorder.Code)
+	}
+	if called {
+		t.Fatal("Expected no callbacks for an invalid signature")
+	}
+}
+
+func TestWebhookHandlerVerification(t *testing.T) {
+	client, err := getstream.New(&getstream.Config{
+		APIKey:    "my_key",
+		APISecret: "my_secret",
+		AppID:     "111111",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	client.WebhookHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/callback?api_key=my_key", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "my_key" {
+		t.Fatal("Expected the API key as response, got:", recorder.Code, recorder.Body.String())
+	}
+}